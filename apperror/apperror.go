@@ -0,0 +1,99 @@
+// Package apperror defines the typed errors services return. Each error
+// carries an HTTP status and a stable code from the i18n catalogs; the app's
+// error handler renders it as an RFC 7807 problem document.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ElvinEga/gofiber_starter/i18n"
+)
+
+// Error is an application error with a stable, machine-readable code.
+type Error struct {
+	Status int
+	Code   string
+	// Details is rendered as an extension member of the problem document;
+	// validation errors are rendered under "errors".
+	Details interface{}
+	// Err is the underlying cause. It is logged, never sent to clients.
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := i18n.T(i18n.DefaultLocale, e.Code, nil)
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails returns a copy of e carrying details.
+func (e *Error) WithDetails(details interface{}) *Error {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+// Wrap returns a copy of e with err recorded as its cause.
+func (e *Error) Wrap(err error) *Error {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+// New creates an error with the given HTTP status and code.
+func New(status int, code string) *Error {
+	return &Error{Status: status, Code: code}
+}
+
+func BadRequest(code string) *Error {
+	return New(http.StatusBadRequest, code)
+}
+
+func Unauthorized(code string) *Error {
+	return New(http.StatusUnauthorized, code)
+}
+
+func Forbidden(code string) *Error {
+	return New(http.StatusForbidden, code)
+}
+
+func NotFound(code string) *Error {
+	return New(http.StatusNotFound, code)
+}
+
+func Conflict(code string) *Error {
+	return New(http.StatusConflict, code)
+}
+
+func PreconditionFailed(code string) *Error {
+	return New(http.StatusPreconditionFailed, code)
+}
+
+func Unprocessable(code string, details interface{}) *Error {
+	return New(http.StatusUnprocessableEntity, code).WithDetails(details)
+}
+
+func TooManyRequests(code string) *Error {
+	return New(http.StatusTooManyRequests, code)
+}
+
+// Internal reports a server-side failure; err is kept for logging.
+func Internal(code string, err error) *Error {
+	return New(http.StatusInternalServerError, code).Wrap(err)
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -9,33 +9,81 @@ type TokenInfo struct {
 	Expiration time.Time
 }
 
-var (
-	tokens = make(map[string]TokenInfo)
+type userCutoff struct {
+	RevokedAt  time.Time
+	Expiration time.Time
+}
+
+// Blacklist tracks revoked access tokens and per-user revocations in memory.
+type Blacklist struct {
+	tokens map[string]TokenInfo
 	mutex  sync.RWMutex
-)
+
+	userCutoffs map[string]userCutoff
+	userMutex   sync.RWMutex
+}
+
+// New returns an empty blacklist.
+func New() *Blacklist {
+	return &Blacklist{
+		tokens:      make(map[string]TokenInfo),
+		userCutoffs: make(map[string]userCutoff),
+	}
+}
 
 // Add adds a token to the blacklist with its expiration time.
-func Add(token string, expiration time.Time) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	tokens[token] = TokenInfo{Expiration: expiration}
+func (b *Blacklist) Add(token string, expiration time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.tokens[token] = TokenInfo{Expiration: expiration}
 }
 
 // IsBlacklisted checks if a token is in the blacklist.
 // It also cleans up expired tokens.
-func IsBlacklisted(token string) bool {
-	mutex.RLock()
-	info, exists := tokens[token]
-	mutex.RUnlock()
+func (b *Blacklist) IsBlacklisted(token string) bool {
+	b.mutex.RLock()
+	info, exists := b.tokens[token]
+	b.mutex.RUnlock()
 	if !exists {
 		return false
 	}
 	if time.Now().After(info.Expiration) {
 		// Remove expired token.
-		mutex.Lock()
-		delete(tokens, token)
-		mutex.Unlock()
+		b.mutex.Lock()
+		delete(b.tokens, token)
+		b.mutex.Unlock()
 		return false
 	}
 	return true
 }
+
+// RevokeUser invalidates every token of the user issued before now. The entry
+// is kept until ttl has elapsed, after which any such token has expired anyway.
+func (b *Blacklist) RevokeUser(userID string, ttl time.Duration) {
+	now := time.Now()
+	b.userMutex.Lock()
+	defer b.userMutex.Unlock()
+	b.userCutoffs[userID] = userCutoff{
+		// Token issue times have microsecond precision.
+		RevokedAt:  now.Truncate(time.Microsecond),
+		Expiration: now.Add(ttl),
+	}
+}
+
+// IsUserRevoked reports whether a token of the user issued at issuedAt was
+// revoked by RevokeUser. It also cleans up expired entries.
+func (b *Blacklist) IsUserRevoked(userID string, issuedAt time.Time) bool {
+	b.userMutex.RLock()
+	cutoff, exists := b.userCutoffs[userID]
+	b.userMutex.RUnlock()
+	if !exists {
+		return false
+	}
+	if time.Now().After(cutoff.Expiration) {
+		b.userMutex.Lock()
+		delete(b.userCutoffs, userID)
+		b.userMutex.Unlock()
+		return false
+	}
+	return issuedAt.Before(cutoff.RevokedAt)
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/seeders"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+)
+
+// newFlags returns a flag set that reports errors instead of exiting, so
+// main prints the full usage text.
+func newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// generatedPassword returns a random password satisfying the default policy.
+func generatedPassword() string {
+	return "Pw-" + utils.GenerateSecureToken(12) + "A1"
+}
+
+func runMigrate(env *cliEnv, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	db := env.app().DB
+
+	switch args[0] {
+	case "up":
+		if err := database.MigrateDB(db); err != nil {
+			return err
+		}
+		fmt.Println("✅ Database is up to date")
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return errUsage
+			}
+			steps = n
+		}
+		if err := database.RollbackDB(db, steps); err != nil {
+			return err
+		}
+		fmt.Println("✅ Rollback complete")
+	case "status":
+		states, err := database.MigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range states {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d  %-30s  %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func runSeed(env *cliEnv, args []string) error {
+	if len(args) > 0 && args[0] == "list" {
+		for _, s := range seeders.Default().Seeders() {
+			envs := "all"
+			if len(s.Environments) > 0 {
+				envs = strings.Join(s.Environments, ",")
+			}
+			fmt.Printf("%-12s  %-10s  %s\n", s.Name, envs, s.Description)
+		}
+		return nil
+	}
+	// Checked again by the seeders; failing here avoids opening the database.
+	if env.cfg.IsProduction() {
+		return seeders.ErrProduction
+	}
+
+	if len(args) > 0 && args[0] == "fake" {
+		fs := newFlags("seed fake")
+		count := fs.Int("count", 100, "number of users")
+		password := fs.String("password", seeders.DefaultFakePassword, "password of every user")
+		role := fs.String("role", models.RoleUser, "role of every user")
+		seed := fs.Uint64("seed", 0, "random seed; 0 picks one")
+		if err := fs.Parse(args[1:]); err != nil || *count < 1 {
+			return errUsage
+		}
+		created, err := seeders.GenerateUsers(env.context(), env.app(), seeders.GenerateOptions{
+			Count:    *count,
+			Password: *password,
+			Role:     *role,
+			Seed:     *seed,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Created %d fake users (password %s)\n", created, *password)
+		return nil
+	}
+
+	fs := newFlags("seed")
+	target := fs.String("env", seeders.EnvDev, "environment")
+	dir := fs.String("fixtures", "", "fixture directory to use instead of the embedded one")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	registry := seeders.Default()
+	if *dir != "" {
+		registry = seeders.NewRegistry()
+		for _, s := range seeders.Default().Seeders() {
+			if s.Name == "fixtures" {
+				s = seeders.FixturesSeeder(os.DirFS(*dir))
+			}
+			registry.MustRegister(s)
+		}
+	}
+
+	ran, err := registry.Run(env.context(), env.app(), *target, fs.Args()...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Seeded %s: %s\n", *target, strings.Join(ran, ", "))
+	return nil
+}
+
+func runUser(env *cliEnv, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	users := env.app().Users
+	ctx := env.context()
+
+	switch args[0] {
+	case "create":
+		fs := newFlags("user create")
+		email := fs.String("email", "", "email address")
+		name := fs.String("name", "", "display name")
+		password := fs.String("password", "", "password")
+		role := fs.String("role", models.RoleUser, "role")
+		if err := fs.Parse(args[1:]); err != nil || *email == "" || *name == "" {
+			return errUsage
+		}
+		generated := *password == ""
+		if generated {
+			*password = generatedPassword()
+		}
+		user, err := users.CreateUser(ctx, services.CreateUserInput{
+			Name:     *name,
+			Email:    *email,
+			Password: *password,
+			Role:     *role,
+			// The operator knows the password, so the user replaces it.
+			MustChangePassword: true,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Created %s (%s) with role %s; the password must be changed on first login\n", user.Email, user.ID, user.Role)
+		if generated {
+			fmt.Println("   password:", *password)
+		}
+	case "promote", "demote":
+		fs := newFlags("user " + args[0])
+		role := fs.String("role", models.RoleAdmin, "role to grant")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 1 {
+			return errUsage
+		}
+		if args[0] == "demote" {
+			*role = models.RoleUser
+		}
+		user, err := users.FindByEmail(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if user, err = users.UpdateRole(ctx, user.ID, *role); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s is now %s; their sessions were ended\n", user.Email, user.Role)
+	case "reset-password":
+		fs := newFlags("user reset-password")
+		password := fs.String("password", "", "new password")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 1 {
+			return errUsage
+		}
+		generated := *password == ""
+		if generated {
+			*password = generatedPassword()
+		}
+		user, err := users.FindByEmail(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if err := users.SetPassword(ctx, user.ID, *password); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Password of %s reset; their sessions were ended and a change is required on next login\n", user.Email)
+		if generated {
+			fmt.Println("   password:", *password)
+		}
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func runSessions(env *cliEnv, args []string) error {
+	if len(args) != 2 || args[0] != "revoke" {
+		return errUsage
+	}
+	users := env.app().Users
+	ctx := env.context()
+
+	user, err := users.FindByEmail(ctx, args[1])
+	if err != nil {
+		return err
+	}
+	if err := users.RevokeSessions(ctx, user.ID); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Sessions of %s revoked\n", user.Email)
+	return nil
+}
+
+func runTokens(env *cliEnv, args []string) error {
+	if len(args) != 1 || args[0] != "purge" {
+		return errUsage
+	}
+	n, err := env.app().Accounts.PurgeExpiredSessions(env.context())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Purged %d refresh tokens\n", n)
+	return nil
+}
+
+func runKeys(env *cliEnv, args []string) error {
+	if len(args) != 1 || args[0] != "rotate" {
+		return errUsage
+	}
+	// Secrets are read from the environment, so rotation prints the values
+	// to deploy: the new secret signs, the old ones still verify until the
+	// tokens they signed have expired.
+	previous := append([]string{env.cfg.JWTSecret}, env.cfg.JWTPreviousSecrets...)
+	fmt.Println("Set these variables and restart the server:")
+	fmt.Println()
+	fmt.Printf("JWT_SECRET=%s\n", utils.GenerateSecureToken(32))
+	fmt.Printf("JWT_PREVIOUS_SECRETS=%s\n", strings.Join(previous, ","))
+	fmt.Println()
+	fmt.Printf("Drop the previous secrets once %s have passed.\n", time.Duration(env.cfg.JWTExpiration)*time.Hour)
+	return nil
+}
+
+func runConfig(env *cliEnv, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errUsage
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(env.cfg.Redacted())
+}
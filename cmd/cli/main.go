@@ -0,0 +1,133 @@
+// Command cli is the operator tool for a deployment: it runs migrations,
+// manages users and sessions, rotates the JWT signing secret and prints the
+// effective configuration. It reads the same environment as the server.
+//
+//	go run ./cmd/cli <command> [arguments]
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+)
+
+const usageText = `usage: cli <command> [arguments]
+
+Database:
+  migrate up                     apply every pending migration
+  migrate down [n]               revert the last n migrations (default 1)
+  migrate status                 list migrations and when they were applied
+
+Seed data:
+  seed [-env E] [-fixtures DIR] [NAME...]
+                                 run the seeders for env dev, test or demo (default dev)
+  seed list                      list the registered seeders
+  seed fake -count N [-password P] [-role R] [-seed S]
+                                 generate N fake users for load testing
+
+Users:
+  user create -email E -name N [-password P] [-role R]
+  user promote [-role R] EMAIL   grant a role (default admin)
+  user demote EMAIL              reset the role to user
+  user reset-password [-password P] EMAIL
+
+Sessions and keys:
+  sessions revoke EMAIL          sign a user out of every session
+  tokens purge                   delete expired and revoked refresh tokens
+  keys rotate                    generate a new JWT signing secret
+
+Configuration:
+  config print                   print the effective configuration, secrets masked
+
+Passwords that are not given are generated and printed.`
+
+// command runs against the loaded configuration. Commands that need the
+// database call app to open it.
+type command func(env *cliEnv, args []string) error
+
+var commands = map[string]command{
+	"migrate":  runMigrate,
+	"seed":     runSeed,
+	"user":     runUser,
+	"sessions": runSessions,
+	"tokens":   runTokens,
+	"keys":     runKeys,
+	"config":   runConfig,
+}
+
+// errUsage makes main print the usage text and exit with status 2.
+var errUsage = errors.New("invalid usage")
+
+// cliEnv holds the configuration and, once opened, the application
+// container.
+type cliEnv struct {
+	cfg config.Config
+	ctr *container.Container
+}
+
+// app opens the database and builds the container on first use.
+func (e *cliEnv) app() *container.Container {
+	if e.ctr == nil {
+		db, err := database.ConnectDB(e.cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if e.ctr, err = container.New(e.cfg, db); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return e.ctr
+}
+
+// context returns the context service calls are made with; audit events
+// record the CLI as their user agent.
+func (e *cliEnv) context() context.Context {
+	return services.WithRequestInfo(context.Background(), services.RequestInfo{UserAgent: "gofiber-cli"})
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usageText)
+		os.Exit(2)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintln(os.Stderr, usageText)
+		os.Exit(2)
+	}
+
+	env := &cliEnv{cfg: config.Load()}
+	if err := run(env, os.Args[2:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, usageText)
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "❌", describe(err))
+		os.Exit(1)
+	}
+}
+
+// describe renders an error for the terminal, including password policy
+// violations.
+func describe(err error) string {
+	appErr, ok := apperror.As(err)
+	if !ok {
+		return err.Error()
+	}
+	msg := appErr.Error()
+	if violations, ok := appErr.Details.([]utils.ValidationError); ok {
+		for _, v := range violations {
+			msg += "\n   - " + v.Message
+		}
+	}
+	return msg
+}
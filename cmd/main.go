@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
 	"github.com/ElvinEga/gofiber_starter/database"
 	"github.com/ElvinEga/gofiber_starter/internal/swaggerui"
 	"github.com/ElvinEga/gofiber_starter/routes"
+	"github.com/ElvinEga/gofiber_starter/utils"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/cors"
 )
@@ -22,23 +26,47 @@ import (
 // @host localhost:8000
 // @BasePath /
 func main() {
-	config.InitConfig()
-	database.ConnectDB()
-	database.SeedSuperAdmin()
-	database.MigrateDB()
+	cfg := config.Load()
+	db, err := database.ConnectDB(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.DBAutoMigrate {
+		if err := database.MigrateDB(db); err != nil {
+			log.Fatalf("database migration failed: %v", err)
+		}
+	}
+
+	ctr, err := container.New(cfg, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bootstrap, err := ctr.Bootstrap.Bootstrap(context.Background())
+	if err != nil {
+		log.Fatalf("refusing to start: %v", err)
+	}
+	if bootstrap.Created != nil {
+		log.Printf("created superadmin %s from BOOTSTRAP_ADMIN_EMAIL; the password must be changed on first login", bootstrap.Created.Email)
+	}
+	if bootstrap.SetupToken != "" {
+		log.Printf("no superadmin exists; create one with POST /api/setup using setup token %s (valid until restart)", bootstrap.SetupToken)
+	}
+	ctr.Accounts.StartPurger(time.Hour)
 
 	app := fiber.New(fiber.Config{
-		BodyLimit: 10 * 1024 * 1024, // 10MB limit
+		BodyLimit:    10 * 1024 * 1024, // 10MB limit
+		ErrorHandler: utils.ErrorHandler,
 	})
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"ETag"},
 		AllowCredentials: true,
 	}))
 	app.Get("/swagger/*", swaggerui.Handler())
 	// app.Static("/docs", "./docs")
-	routes.SetupRoutes(app)
+	routes.SetupRoutes(app, ctr)
 
 	log.Fatal(app.Listen(":8000"))
 }
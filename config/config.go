@@ -4,36 +4,175 @@ import (
 	"github.com/joho/godotenv"
 	"os"
 	"strconv"
+	"strings"
+)
+
+// Registration modes accepted by REGISTRATION_MODE.
+const (
+	RegistrationOpen       = "open"
+	RegistrationInviteOnly = "invite_only"
+	RegistrationClosed     = "closed"
+)
+
+// Database drivers accepted by DB_DRIVER.
+const (
+	DriverSQLite    = "sqlite"
+	DriverPostgres  = "postgres"
+	DriverMySQL     = "mysql"
+	DriverSQLServer = "sqlserver"
+)
+
+// Storage backends accepted by STORAGE_DRIVER.
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+// Environments accepted by APP_ENV.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
 )
 
 type Config struct {
-	DBPath             string
-	DatabaseURL        string
-	GoogleClientID     string
-	GoogleClientSecret string
-	GoogleRedirectURL  string
-	JWTSecret          string
-	JWTExpiration      int
-	ServerPort         string
-	FrontendURL        string
+	AppEnv                 string
+	DBDriver               string
+	DBPath                 string
+	DatabaseURL            string
+	DatabaseReplicaURLs    []string
+	DBAutoMigrate          bool
+	DBMaxOpenConns         int
+	DBMaxIdleConns         int
+	DBConnMaxLifetimeMin   int
+	DBConnMaxIdleTimeMin   int
+	DBStatementTimeoutMS   int
+	DBSQLiteWAL            bool
+	DBSQLiteBusyTimeoutMS  int
+	DBSlowQueryMS          int
+	DBLogLevel             string
+	DBConnectRetries       int
+	DBConnectBackoffMS     int
+	GoogleClientID         string
+	GoogleClientSecret     string
+	GoogleRedirectURL      string
+	JWTSecret              string
+	JWTPreviousSecrets     []string
+	JWTExpiration          int
+	ServerPort             string
+	FrontendURL            string
+	RegistrationMode       string
+	AllowedEmailDomains    []string
+	BlockedEmailDomains    []string
+	BlockDisposableEmails  bool
+	InvitationTTLHours     int
+	ImpersonationTTL       int
+	DeletionGraceDays      int
+	SMTPHost               string
+	SMTPPort               string
+	SMTPUsername           string
+	SMTPPassword           string
+	MailFrom               string
+	StorageDriver          string
+	UploadDir              string
+	StoragePublicURL       string
+	S3Endpoint             string
+	S3Region               string
+	S3Bucket               string
+	S3AccessKeyID          string
+	S3SecretAccessKey      string
+	S3ForcePathStyle       bool
+	AvatarMaxBytes         int
+	AvatarSize             int
+	AvatarThumbnailSize    int
+	PasswordMinLength      int
+	PasswordMaxBytes       int
+	PasswordRequireUpper   bool
+	PasswordRequireLower   bool
+	PasswordRequireDigit   bool
+	PasswordRequireSymbol  bool
+	PasswordHistorySize    int
+	BreachedPasswordsDir   string
+	PasswordHashAlgorithm  string
+	Argon2MemoryKiB        int
+	Argon2Iterations       int
+	Argon2Parallelism      int
+	BcryptCost             int
+	BootstrapAdminName     string
+	BootstrapAdminEmail    string
+	BootstrapAdminPassword string
 }
 
-var AppConfig Config
-
-func InitConfig() {
-	// Load environment variables from .env (if present)
+// Load reads the configuration from the environment, after loading .env when
+// present.
+func Load() Config {
 	_ = godotenv.Load()
 
-	AppConfig = Config{
-		DBPath:             getEnv("DB_PATH", "gofiber.db"),
-		DatabaseURL:        getEnv("DATABASE_URL", ""),
-		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
-		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
-		GoogleRedirectURL:  getEnv("GOOGLE_REDIRECT_URL", ""),
-		JWTSecret:          getEnv("JWT_SECRET", "secret"),
-		JWTExpiration:      getEnvAsInt("JWT_EXPIRATION", 72),
-		ServerPort:         getEnv("SERVER_PORT", "8000"),
-		FrontendURL:        getEnv("FRONTEND_URL", "http://localhost:3000"),
+	return Config{
+		AppEnv:                 strings.ToLower(getEnv("APP_ENV", EnvDevelopment)),
+		DBDriver:               strings.ToLower(getEnv("DB_DRIVER", "")),
+		DBPath:                 getEnv("DB_PATH", "gofiber.db"),
+		DatabaseURL:            getEnv("DATABASE_URL", ""),
+		DatabaseReplicaURLs:    getEnvAsList("DATABASE_REPLICA_URLS"),
+		DBAutoMigrate:          getEnvAsBool("DB_AUTO_MIGRATE", true),
+		DBMaxOpenConns:         getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:         getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
+		DBConnMaxLifetimeMin:   getEnvAsInt("DB_CONN_MAX_LIFETIME_MINUTES", 30),
+		DBConnMaxIdleTimeMin:   getEnvAsInt("DB_CONN_MAX_IDLE_TIME_MINUTES", 5),
+		DBStatementTimeoutMS:   getEnvAsInt("DB_STATEMENT_TIMEOUT_MS", 30000),
+		DBSQLiteWAL:            getEnvAsBool("DB_SQLITE_WAL", true),
+		DBSQLiteBusyTimeoutMS:  getEnvAsInt("DB_SQLITE_BUSY_TIMEOUT_MS", 5000),
+		DBSlowQueryMS:          getEnvAsInt("DB_SLOW_QUERY_MS", 200),
+		DBLogLevel:             strings.ToLower(getEnv("DB_LOG_LEVEL", "warn")),
+		DBConnectRetries:       getEnvAsInt("DB_CONNECT_RETRIES", 5),
+		DBConnectBackoffMS:     getEnvAsInt("DB_CONNECT_BACKOFF_MS", 500),
+		GoogleClientID:         getEnv("GOOGLE_CLIENT_ID", ""),
+		GoogleClientSecret:     getEnv("GOOGLE_CLIENT_SECRET", ""),
+		GoogleRedirectURL:      getEnv("GOOGLE_REDIRECT_URL", ""),
+		JWTSecret:              getEnv("JWT_SECRET", "secret"),
+		JWTPreviousSecrets:     getEnvAsList("JWT_PREVIOUS_SECRETS"),
+		JWTExpiration:          getEnvAsInt("JWT_EXPIRATION", 72),
+		ServerPort:             getEnv("SERVER_PORT", "8000"),
+		FrontendURL:            getEnv("FRONTEND_URL", "http://localhost:3000"),
+		RegistrationMode:       strings.ToLower(getEnv("REGISTRATION_MODE", RegistrationOpen)),
+		AllowedEmailDomains:    getEnvAsSlice("ALLOWED_EMAIL_DOMAINS"),
+		BlockedEmailDomains:    getEnvAsSlice("BLOCKED_EMAIL_DOMAINS"),
+		BlockDisposableEmails:  getEnvAsBool("BLOCK_DISPOSABLE_EMAILS", false),
+		InvitationTTLHours:     getEnvAsInt("INVITATION_TTL_HOURS", 168),
+		ImpersonationTTL:       getEnvAsInt("IMPERSONATION_TTL_MINUTES", 15),
+		DeletionGraceDays:      getEnvAsInt("ACCOUNT_DELETION_GRACE_DAYS", 30),
+		SMTPHost:               getEnv("SMTP_HOST", ""),
+		SMTPPort:               getEnv("SMTP_PORT", "587"),
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+		MailFrom:               getEnv("MAIL_FROM", "no-reply@example.com"),
+		StorageDriver:          strings.ToLower(getEnv("STORAGE_DRIVER", StorageLocal)),
+		UploadDir:              getEnv("UPLOAD_DIR", "data/uploads"),
+		StoragePublicURL:       getEnv("STORAGE_PUBLIC_URL", ""),
+		S3Endpoint:             getEnv("S3_ENDPOINT", ""),
+		S3Region:               getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:               getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:          getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey:      getEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3ForcePathStyle:       getEnvAsBool("S3_FORCE_PATH_STYLE", false),
+		AvatarMaxBytes:         getEnvAsInt("AVATAR_MAX_BYTES", 5*1024*1024),
+		AvatarSize:             getEnvAsInt("AVATAR_SIZE", 512),
+		AvatarThumbnailSize:    getEnvAsInt("AVATAR_THUMBNAIL_SIZE", 128),
+		PasswordMinLength:      getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxBytes:       getEnvAsInt("PASSWORD_MAX_BYTES", 72),
+		PasswordRequireUpper:   getEnvAsBool("PASSWORD_REQUIRE_UPPER", true),
+		PasswordRequireLower:   getEnvAsBool("PASSWORD_REQUIRE_LOWER", true),
+		PasswordRequireDigit:   getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol:  getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordHistorySize:    getEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+		BreachedPasswordsDir:   getEnv("BREACHED_PASSWORDS_DIR", ""),
+		PasswordHashAlgorithm:  strings.ToLower(getEnv("PASSWORD_HASH_ALGORITHM", "argon2id")),
+		Argon2MemoryKiB:        getEnvAsInt("ARGON2_MEMORY_KIB", 19456),
+		Argon2Iterations:       getEnvAsInt("ARGON2_ITERATIONS", 2),
+		Argon2Parallelism:      getEnvAsInt("ARGON2_PARALLELISM", 1),
+		BcryptCost:             getEnvAsInt("BCRYPT_COST", 10),
+		BootstrapAdminName:     getEnv("BOOTSTRAP_ADMIN_NAME", "Super Admin"),
+		BootstrapAdminEmail:    getEnv("BOOTSTRAP_ADMIN_EMAIL", ""),
+		BootstrapAdminPassword: getEnv("BOOTSTRAP_ADMIN_PASSWORD", ""),
 	}
 }
 
@@ -52,3 +191,82 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice reads a comma separated list, lowercased, dropping empty
+// entries.
+func getEnvAsSlice(key string) []string {
+	items := getEnvAsList(key)
+	for i, item := range items {
+		items[i] = strings.ToLower(item)
+	}
+	return items
+}
+
+// getEnvAsList reads a comma separated list as is, dropping empty entries.
+func getEnvAsList(key string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// IsProduction reports whether APP_ENV is production.
+func (c Config) IsProduction() bool {
+	return c.AppEnv == EnvProduction
+}
+
+// Driver returns the database driver: DB_DRIVER when set, otherwise Postgres
+// when DATABASE_URL is set and SQLite when it is not.
+func (c Config) Driver() string {
+	switch {
+	case c.DBDriver != "":
+		return c.DBDriver
+	case c.DatabaseURL != "":
+		return DriverPostgres
+	default:
+		return DriverSQLite
+	}
+}
+
+// Redacted returns a copy of c with secrets masked, safe to print or log.
+func (c Config) Redacted() Config {
+	mask := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "********"
+	}
+	c.DatabaseURL = mask(c.DatabaseURL)
+	replicas := make([]string, len(c.DatabaseReplicaURLs))
+	for i, dsn := range c.DatabaseReplicaURLs {
+		replicas[i] = mask(dsn)
+	}
+	c.DatabaseReplicaURLs = replicas
+	c.GoogleClientSecret = mask(c.GoogleClientSecret)
+	c.JWTSecret = mask(c.JWTSecret)
+	previous := make([]string, len(c.JWTPreviousSecrets))
+	for i, secret := range c.JWTPreviousSecrets {
+		previous[i] = mask(secret)
+	}
+	c.JWTPreviousSecrets = previous
+	c.SMTPPassword = mask(c.SMTPPassword)
+	c.S3SecretAccessKey = mask(c.S3SecretAccessKey)
+	c.BootstrapAdminPassword = mask(c.BootstrapAdminPassword)
+	return c
+}
@@ -0,0 +1,103 @@
+// Package container wires the application's dependencies. A Container is
+// built once at startup, or once per test, and handed to the routes; several
+// containers can live side by side without sharing state.
+package container
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/blacklist"
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/mailer"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/storage"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"gorm.io/gorm"
+)
+
+// Container holds the configuration, infrastructure and services of one
+// application instance.
+type Container struct {
+	Config    config.Config
+	DB        *gorm.DB
+	Store     repositories.Store
+	Blacklist *blacklist.Blacklist
+	Tokens    *utils.TokenService
+	Mailer    mailer.Mailer
+	Google    *utils.GoogleOAuth
+	Storage   storage.Storage
+	Hasher    utils.PasswordHasher
+
+	Auth          *services.AuthService
+	Users         *services.UserService
+	Accounts      *services.AccountService
+	Invitations   *services.InvitationService
+	Impersonation *services.ImpersonationService
+	Audit         *services.AuditService
+	Bootstrap     *services.BootstrapService
+	Avatars       *services.AvatarService
+}
+
+// Option customises a Container before its services are built.
+type Option func(*Container)
+
+// WithMailer replaces the mailer selected from the configuration.
+func WithMailer(m mailer.Mailer) Option {
+	return func(c *Container) {
+		c.Mailer = m
+	}
+}
+
+// WithStorage replaces the file storage selected from the configuration.
+func WithStorage(s storage.Storage) Option {
+	return func(c *Container) {
+		c.Storage = s
+	}
+}
+
+// New builds a Container around cfg and db. It fails when the password
+// hashing configuration or the access token lifetime is invalid.
+func New(cfg config.Config, db *gorm.DB, opts ...Option) (*Container, error) {
+	if cfg.JWTExpiration <= 0 {
+		return nil, fmt.Errorf("invalid JWT_EXPIRATION %d: want a positive number of hours", cfg.JWTExpiration)
+	}
+	hasher, err := utils.NewPasswordHasher(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password hashing configuration: %w", err)
+	}
+	c := &Container{
+		Config:    cfg,
+		DB:        db,
+		Store:     repositories.NewGormStore(db),
+		Blacklist: blacklist.New(),
+		Mailer:    mailer.New(cfg),
+		Google:    utils.NewGoogleOAuth(cfg),
+		Storage:   storage.New(cfg),
+		Hasher:    hasher,
+	}
+	c.Tokens = utils.NewTokenService(cfg.JWTSecret, time.Duration(cfg.JWTExpiration)*time.Hour, c.Blacklist, cfg.JWTPreviousSecrets...)
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	deps := services.Deps{
+		Config:  c.Config,
+		Store:   c.Store,
+		Tokens:  c.Tokens,
+		Mailer:  c.Mailer,
+		Google:  c.Google,
+		Storage: c.Storage,
+		Hasher:  c.Hasher,
+	}
+	c.Auth = services.NewAuthService(deps)
+	c.Users = services.NewUserService(deps)
+	c.Accounts = services.NewAccountService(deps)
+	c.Invitations = services.NewInvitationService(deps)
+	c.Impersonation = services.NewImpersonationService(deps)
+	c.Audit = services.NewAuditService(deps)
+	c.Bootstrap = services.NewBootstrapService(deps, c.Users)
+	c.Avatars = services.NewAvatarService(deps)
+	return c, nil
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/requests"
+	"github.com/ElvinEga/gofiber_starter/responses"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type AdminController struct {
+	users         *services.UserService
+	invitations   *services.InvitationService
+	impersonation *services.ImpersonationService
+	audit         *services.AuditService
+}
+
+func NewAdminController(users *services.UserService, invitations *services.InvitationService, impersonation *services.ImpersonationService, audit *services.AuditService) *AdminController {
+	return &AdminController{users: users, invitations: invitations, impersonation: impersonation, audit: audit}
+}
+
+// CreateInvitation godoc
+// @Summary Create a registration invitation
+// @Description Issue an invitation code, optionally bound to an email address
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param requests.CreateInvitationRequest body requests.CreateInvitationRequest true "Invitation Request"
+// @Success 201 {object} models.Invitation
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Router /api/admin/invitations [post]
+func (ac *AdminController) CreateInvitation(c fiber.Ctx) error {
+	var req requests.CreateInvitationRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	invitation, err := ac.invitations.Create(requestContext(c), services.CreateInvitationInput{
+		Email:          req.Email,
+		ExpiresInHours: req.ExpiresInHours,
+	})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Invitation created",
+		"data":    invitation,
+	})
+}
+
+// ImpersonateUser godoc
+// @Summary Impersonate a user
+// @Description Issue a short-lived access token acting as the target user. Every call is audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Target user ID"
+// @Param requests.ImpersonateRequest body requests.ImpersonateRequest false "Impersonation Request"
+// @Success 200 {object} responses.ImpersonationResponse
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 403 {object} utils.ProblemDetails
+// @Failure 404 {object} utils.ProblemDetails
+// @Router /api/admin/users/{id}/impersonate [post]
+func (ac *AdminController) ImpersonateUser(c fiber.Ctx) error {
+	var req requests.ImpersonateRequest
+	if len(c.Body()) > 0 {
+		if err := utils.BindAndValidate(c, &req); err != nil {
+			return err
+		}
+	}
+	targetID, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := ac.impersonation.Impersonate(requestContext(c), targetID, req.Reason)
+	if err != nil {
+		return err
+	}
+
+	user := responses.ToUserResponse(result.User)
+	user.Impersonation = &responses.ImpersonationContext{ImpersonatorID: result.ImpersonatorID.String()}
+
+	return c.JSON(responses.ImpersonationResponse{
+		Status:      "success",
+		Message:     "Impersonation token issued",
+		AccessToken: result.AccessToken,
+		ExpiresAt:   result.ExpiresAt,
+		User:        user,
+	})
+}
+
+// UpdateUserRole godoc
+// @Summary Change a user's role
+// @Description Assign a new role to a user
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param requests.UpdateRoleRequest body requests.UpdateRoleRequest true "Role Request"
+// @Success 200 {object} responses.UserResponse
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 404 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Router /api/admin/users/{id}/role [put]
+func (ac *AdminController) UpdateUserRole(c fiber.Ctx) error {
+	var req requests.UpdateRoleRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	targetID, err := pathUserID(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := ac.users.UpdateRole(requestContext(c), targetID, req.Role)
+	if err != nil {
+		return err
+	}
+	return utils.HandleSuccess(c, "Role updated successfully", responses.ToUserResponse(*user))
+}
+
+// ListAuditEvents godoc
+// @Summary List audit events
+// @Description Query the security audit log, newest first
+// @Tags Admin
+// @Produce json
+// @Param action query string false "Action, e.g. auth.login"
+// @Param outcome query string false "success or failure"
+// @Param actor_id query string false "Actor user ID"
+// @Param target_id query string false "Target user ID"
+// @Param from query string false "RFC3339 lower bound"
+// @Param to query string false "RFC3339 upper bound"
+// @Param page query int false "Page"
+// @Param limit query int false "Page size"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} utils.ProblemDetails
+// @Router /api/admin/audit-events [get]
+func (ac *AdminController) ListAuditEvents(c fiber.Ctx) error {
+	filter := repositories.AuditEventFilter{
+		Action:  c.Query("action"),
+		Outcome: c.Query("outcome"),
+	}
+	var err error
+	if filter.ActorID, err = uuidQuery(c, "actor_id"); err != nil {
+		return err
+	}
+	if filter.TargetID, err = uuidQuery(c, "target_id"); err != nil {
+		return err
+	}
+	if filter.From, err = timeQuery(c, "from"); err != nil {
+		return err
+	}
+	if filter.To, err = timeQuery(c, "to"); err != nil {
+		return err
+	}
+
+	_, limit, offset := utils.GetPagination(c)
+	events, total, err := ac.audit.List(requestContext(c), filter, limit, offset)
+	if err != nil {
+		return err
+	}
+	return c.JSON(utils.PaginationResponse(c, events, total))
+}
+
+// uuidQuery parses an optional UUID query parameter.
+func uuidQuery(c fiber.Ctx, param string) (*uuid.UUID, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, apperror.BadRequest(i18n.ErrInvalidFilter).WithDetails(fiber.Map{"parameter": param})
+	}
+	return &id, nil
+}
+
+// timeQuery parses an optional RFC 3339 query parameter.
+func timeQuery(c fiber.Ctx, param string) (*time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperror.BadRequest(i18n.ErrInvalidFilter).WithDetails(fiber.Map{"parameter": param})
+	}
+	return &t, nil
+}
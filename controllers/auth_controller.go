@@ -1,42 +1,210 @@
 package controllers
 
 import (
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/requests"
+	"github.com/ElvinEga/gofiber_starter/responses"
 	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
 	"github.com/gofiber/fiber/v3"
 )
 
-func Register(c fiber.Ctx) error {
-	return services.Register(c)
+type AuthController struct {
+	auth  *services.AuthService
+	users *services.UserService
 }
 
-func Login(c fiber.Ctx) error {
-	return services.Login(c)
+func NewAuthController(auth *services.AuthService, users *services.UserService) *AuthController {
+	return &AuthController{auth: auth, users: users}
 }
 
-func GoogleSSO(c fiber.Ctx) error {
-	return services.GoogleLogin(c)
+func newAuthResponse(user models.User, accessToken, refreshToken, message string) responses.AuthResponse {
+	return responses.AuthResponse{
+		Status:       "success",
+		Message:      message,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         responses.ToUserResponse(user),
+	}
 }
 
-func GoogleCallback(c fiber.Ctx) error {
-	return services.GoogleCallback(c)
+// Register godoc
+// @Summary Register a new user
+// @Description Create a new user account with auto-generated username
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param requests.RegisterRequest body requests.RegisterRequest true "Register Request"
+// @Success 201 {object} responses.AuthResponse
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 403 {object} utils.ProblemDetails
+// @Failure 409 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Failure 500 {object} utils.ProblemDetails
+// @Router /api/register [post]
+func (ac *AuthController) Register(c fiber.Ctx) error {
+	var req requests.RegisterRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	result, err := ac.auth.Register(requestContext(c), services.RegisterInput{
+		Name:       req.Name,
+		Email:      req.Email,
+		Password:   req.Password,
+		InviteCode: req.InviteCode,
+	})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(newAuthResponse(result.User, result.AccessToken, result.RefreshToken, "Registration successful"))
+}
+
+// Login godoc
+// @Summary Login a user
+// @Description Login a user with email and password
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param requests.LoginRequest body requests.LoginRequest true "Login Request"
+// @Success 200 {object} responses.AuthResponse
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 401 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Failure 500 {object} utils.ProblemDetails
+// @Router /api/login [post]
+func (ac *AuthController) Login(c fiber.Ctx) error {
+	var req requests.LoginRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	result, err := ac.auth.Login(requestContext(c), services.LoginInput{
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(newAuthResponse(result.User, result.AccessToken, result.RefreshToken, "Login successful"))
+}
+
+func (ac *AuthController) GoogleSSO(c fiber.Ctx) error {
+	url := ac.auth.GoogleAuthURL()
+	return c.Redirect().Status(fiber.StatusTemporaryRedirect).To(url)
+}
+
+// GoogleCallback handles the callback from Google OAuth.
+func (ac *AuthController) GoogleCallback(c fiber.Ctx) error {
+	result, err := ac.auth.GoogleSignIn(requestContext(c), c.Query("code"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"token": result.Token, "user": result.User})
 }
 
-func Logout(c fiber.Ctx) error {
-	return services.Logout(c)
+// Logout godoc
+// @Summary Logout a user
+// @Description Invalidate the current JWT token by blacklisting it
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.AuthResponse
+// @Failure 401 {object} utils.ProblemDetails
+// @Router /api/logout [post]
+func (ac *AuthController) Logout(c fiber.Ctx) error {
+	token, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+	if err := ac.auth.Logout(requestContext(c), token); err != nil {
+		return err
+	}
+
+	return c.JSON(responses.AuthResponse{
+		Status:  "success",
+		Message: "Logout successful",
+	})
 }
 
-func RefreshToken(c fiber.Ctx) error {
-	return services.RefreshToken(c)
+func (ac *AuthController) RefreshToken(c fiber.Ctx) error {
+	var req requests.RefreshTokenRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	result, err := ac.auth.Refresh(requestContext(c), req.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":        "success",
+		"message":       "Token refreshed successfully",
+		"access_token":  result.AccessToken,
+		"refresh_token": result.RefreshToken,
+		"user":          responses.ToUserResponse(result.User),
+	})
 }
 
-func VerifyEmail(c fiber.Ctx) error {
-	return services.VerifyEmail(c)
+func (ac *AuthController) VerifyEmail(c fiber.Ctx) error {
+	if err := ac.auth.VerifyEmail(requestContext(c), c.Query("token")); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Email verified successfully",
+	})
 }
 
-func RequestPasswordReset(c fiber.Ctx) error {
-	return services.RequestPasswordReset(c)
+func (ac *AuthController) RequestPasswordReset(c fiber.Ctx) error {
+	var req requests.ForgotPasswordRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := ac.auth.RequestPasswordReset(requestContext(c), req.Email); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "If your email is registered, you will receive a password reset link",
+	})
+}
+
+func (ac *AuthController) ResetPassword(c fiber.Ctx) error {
+	var req requests.ResetPasswordRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	err := ac.auth.ResetPassword(requestContext(c), services.ResetPasswordInput{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Password reset successfully",
+	})
 }
 
-func ResetPassword(c fiber.Ctx) error {
-	return services.ResetPassword(c)
+// ConfirmEmailChange godoc
+// @Summary Confirm an email address change
+// @Description Swap the pending email in using the token sent to the new address
+// @Tags Auth
+// @Produce json
+// @Param token query string true "Confirmation token"
+// @Success 200 {object} responses.UserResponse
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 404 {object} utils.ProblemDetails
+// @Failure 409 {object} utils.ProblemDetails
+// @Router /api/auth/confirm-email [get]
+func (ac *AuthController) ConfirmEmailChange(c fiber.Ctx) error {
+	user, err := ac.users.ConfirmEmailChange(requestContext(c), c.Query("token"))
+	if err != nil {
+		return err
+	}
+	return utils.HandleSuccess(c, "Email changed successfully", responses.ToUserResponse(*user))
 }
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// requestContext builds the service context for c, carrying the caller's
+// address, locale and identity.
+func requestContext(c fiber.Ctx) context.Context {
+	info := services.RequestInfo{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Locale:    utils.Locale(c),
+	}
+	if id, err := currentUserID(c); err == nil {
+		info.UserID = &id
+	}
+	if impersonatorID, ok := c.Locals("impersonatorID").(string); ok {
+		info.ImpersonatorID = impersonatorID
+	}
+	return services.WithRequestInfo(c.Context(), info)
+}
+
+// currentUserID returns the ID set by the JWT middleware.
+func currentUserID(c fiber.Ctx) (uuid.UUID, error) {
+	raw, _ := c.Locals("userID").(string)
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, apperror.Unauthorized(i18n.ErrUnauthorized)
+	}
+	return id, nil
+}
+
+// pathUserID parses the :id route parameter.
+func pathUserID(c fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, apperror.BadRequest(i18n.ErrInvalidUserID)
+	}
+	return id, nil
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(c fiber.Ctx) (string, error) {
+	authHeader := c.Get(fiber.HeaderAuthorization)
+	if authHeader == "" {
+		return "", apperror.BadRequest(i18n.ErrMissingAuthHeader)
+	}
+
+	const bearerPrefix = "Bearer "
+	if len(authHeader) <= len(bearerPrefix) || !strings.HasPrefix(authHeader, bearerPrefix) {
+		return "", apperror.BadRequest(i18n.ErrInvalidAuthHeader)
+	}
+	return authHeader[len(bearerPrefix):], nil
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/gofiber/fiber/v3"
+)
+
+// setVersionETag sets the ETag header to a record version.
+func setVersionETag(c fiber.Ctx, version int64) {
+	c.Set(fiber.HeaderETag, `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// ifMatchVersion parses the If-Match header as a single strong ETag set by
+// setVersionETag. It returns 0 when the header is absent or "*", and 412 when
+// the header names anything else, which can never match.
+func ifMatchVersion(c fiber.Ctx) (int64, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	unquoted, ok := strings.CutPrefix(header, `"`)
+	if ok {
+		unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if !ok || err != nil || version <= 0 {
+		return 0, apperror.PreconditionFailed(i18n.ErrPreconditionFailed)
+	}
+	return version, nil
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"github.com/ElvinEga/gofiber_starter/requests"
+	"github.com/ElvinEga/gofiber_starter/responses"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+type SetupController struct {
+	bootstrap *services.BootstrapService
+}
+
+func NewSetupController(bootstrap *services.BootstrapService) *SetupController {
+	return &SetupController{bootstrap: bootstrap}
+}
+
+// CompleteSetup godoc
+// @Summary Create the first superadmin
+// @Description Consume the one-time setup token logged at startup to create the first superadmin
+// @Tags Setup
+// @Accept json
+// @Produce json
+// @Param requests.SetupRequest body requests.SetupRequest true "Setup Request"
+// @Success 201 {object} responses.UserResponse
+// @Failure 403 {object} utils.ProblemDetails
+// @Failure 404 {object} utils.ProblemDetails
+// @Failure 409 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Router /api/setup [post]
+func (sc *SetupController) CompleteSetup(c fiber.Ctx) error {
+	var req requests.SetupRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	user, err := sc.bootstrap.CompleteSetup(requestContext(c), services.CompleteSetupInput{
+		Token:    req.Token,
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Superadmin created",
+		"data":    responses.ToUserResponse(*user),
+	})
+}
@@ -1,18 +1,377 @@
 package controllers
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/requests"
+	"github.com/ElvinEga/gofiber_starter/responses"
 	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
 	"github.com/gofiber/fiber/v3"
 )
 
-func GetUserProfile(c fiber.Ctx) error {
-	return services.GetUserProfile(c)
+type UserController struct {
+	users    *services.UserService
+	accounts *services.AccountService
+	avatars  *services.AvatarService
+}
+
+func NewUserController(users *services.UserService, accounts *services.AccountService, avatars *services.AvatarService) *UserController {
+	return &UserController{users: users, accounts: accounts, avatars: avatars}
+}
+
+// Profile godoc
+// @Summary Get user profile
+// @Description Retrieve the user's profile information
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.UserResponse
+// @Failure 401 {object} responses.UserResponse
+// @Router /api/profile [get]
+func (uc *UserController) GetUserProfile(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	user, err := uc.users.Profile(requestContext(c), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := responses.ToUserResponse(*user)
+	if impersonatorID, ok := c.Locals("impersonatorID").(string); ok {
+		resp.Impersonation = &responses.ImpersonationContext{ImpersonatorID: impersonatorID}
+	}
+	setVersionETag(c, user.Version)
+	return c.JSON(resp)
 }
 
-func UpdateUser(c fiber.Ctx) error {
-	return services.UpdateUser(c)
+// UpdateUser godoc
+// @Summary Update user profile
+// @Description Change the non-empty profile fields: name, username, avatar_url, bio, locale, timezone, phone, and metadata, which is replaced as a whole. Send the ETag of the profile as If-Match to refuse the update when the profile changed since it was read.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param If-Match header string false "ETag returned by GET /api/user/profile"
+// @Param request body requests.UpdateUserRequest true "Profile fields"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} utils.ProblemDetails
+// @Failure 412 {object} utils.ProblemDetails
+// @Router /api/user/profile [put]
+func (uc *UserController) UpdateUser(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	ifVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	var req requests.UpdateUserRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	user, err := uc.users.UpdateProfile(requestContext(c), userID, services.UpdateProfileInput{
+		Name:      req.Name,
+		Username:  req.Username,
+		AvatarURL: req.AvatarURL,
+		Bio:       req.Bio,
+		Locale:    req.Locale,
+		Timezone:  req.Timezone,
+		Phone:     req.Phone,
+		Metadata:  req.Metadata,
+		IfVersion: ifVersion,
+	})
+	if err != nil {
+		return err
+	}
+	setVersionETag(c, user.Version)
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "User updated successfully",
+		"data":    responses.ToUserResponse(*user),
+	})
 }
 
-func ChangePassword(c fiber.Ctx) error {
-	return services.ChangePassword(c)
+// PatchUser godoc
+// @Summary Patch user profile
+// @Description Change profile fields with a JSON Merge Patch (application/merge-patch+json, or application/json) or a JSON Patch (application/json-patch+json). Only the profile fields of PUT /api/user/profile can be changed; a merge patch merges into metadata, and null clears a field. Send the ETag of the profile as If-Match to refuse the patch when the profile changed since it was read.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param If-Match header string false "ETag returned by GET /api/user/profile"
+// @Param request body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 409 {object} utils.ProblemDetails
+// @Failure 412 {object} utils.ProblemDetails
+// @Failure 415 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Router /api/user/profile [patch]
+func (uc *UserController) PatchUser(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	ifVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	format, err := patchFormat(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := uc.users.PatchProfile(requestContext(c), userID, services.PatchProfileInput{
+		Format:    format,
+		Patch:     c.Body(),
+		IfVersion: ifVersion,
+	})
+	if err != nil {
+		return err
+	}
+	setVersionETag(c, user.Version)
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "User updated successfully",
+		"data":    responses.ToUserResponse(*user),
+	})
+}
+
+// patchFormat picks the patch format from the Content-Type header. Plain
+// JSON is read as a merge patch, which is what clients sending a partial
+// object mean.
+func patchFormat(c fiber.Ctx) (services.PatchFormat, error) {
+	mediaType, _, _ := strings.Cut(c.Get(fiber.HeaderContentType), ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "application/merge-patch+json", fiber.MIMEApplicationJSON:
+		return services.MergePatch, nil
+	case "application/json-patch+json":
+		return services.JSONPatch, nil
+	default:
+		return 0, apperror.New(fiber.StatusUnsupportedMediaType, i18n.ErrUnsupportedMediaType)
+	}
+}
+
+func (uc *UserController) ChangePassword(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	var req requests.ChangePasswordRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	result, err := uc.users.ChangePassword(requestContext(c), userID, services.ChangePasswordInput{
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+		RefreshToken:    req.RefreshToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	response := fiber.Map{
+		"status":  "success",
+		"message": "Password updated successfully",
+	}
+	if result.AccessToken != "" {
+		response["access_token"] = result.AccessToken
+	}
+	return c.JSON(response)
+}
+
+// RequestEmailChange godoc
+// @Summary Request an email address change
+// @Description Store the new address as pending and send a confirmation link to it, plus a notice to the current address. Authorize with the current password. Accounts without a password first call this without a token to get one at the current address, then call it again with the token and the same new address.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param requests.ChangeEmailRequest body requests.ChangeEmailRequest true "Change Email Request"
+// @Success 200 {object} map[string]interface{}
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 401 {object} utils.ProblemDetails
+// @Failure 409 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Router /api/user/email [post]
+func (uc *UserController) RequestEmailChange(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	var req requests.ChangeEmailRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	requested, err := uc.users.RequestEmailChange(requestContext(c), userID, services.ChangeEmailInput{
+		NewEmail: req.NewEmail,
+		Password: req.Password,
+		Token:    req.Token,
+	})
+	if err != nil {
+		return err
+	}
+	if !requested {
+		c.Status(fiber.StatusAccepted)
+		return utils.HandleSuccess(c, "Authorization sent to the current email address; repeat the request with the emailed token")
+	}
+	return utils.HandleSuccess(c, "Confirmation sent to the new email address")
+}
+
+// UploadAvatar godoc
+// @Summary Upload an avatar
+// @Description Upload a JPEG, PNG or GIF as the "avatar" field of a multipart form. The type is detected from the file's content. The image is cropped to a centred square and stored as an avatar and a thumbnail, replacing any avatar uploaded before. Send the ETag of the profile as If-Match to refuse the upload when the profile changed since it was read.
+// @Tags User
+// @Accept multipart/form-data
+// @Produce json
+// @Param If-Match header string false "ETag returned by GET /api/user/profile"
+// @Param avatar formData file true "Image file"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 412 {object} utils.ProblemDetails
+// @Failure 413 {object} utils.ProblemDetails
+// @Failure 415 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Router /api/user/avatar [post]
+func (uc *UserController) UploadAvatar(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	ifVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		return apperror.BadRequest(i18n.ErrFileRequired)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return apperror.BadRequest(i18n.ErrFileRequired).Wrap(err)
+	}
+	defer file.Close()
+
+	user, err := uc.avatars.Upload(requestContext(c), userID, services.UploadAvatarInput{
+		File:      file,
+		IfVersion: ifVersion,
+	})
+	if err != nil {
+		return err
+	}
+	setVersionETag(c, user.Version)
+	return utils.HandleSuccess(c, "Avatar updated successfully", responses.ToUserResponse(*user))
+}
+
+// DeleteAvatar godoc
+// @Summary Remove the avatar
+// @Description Clear the avatar from the profile and delete its files when it was uploaded
+// @Tags User
+// @Produce json
+// @Param If-Match header string false "ETag returned by GET /api/user/profile"
+// @Success 200 {object} map[string]interface{}
+// @Failure 412 {object} utils.ProblemDetails
+// @Router /api/user/avatar [delete]
+func (uc *UserController) DeleteAvatar(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	ifVersion, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	user, err := uc.avatars.Delete(requestContext(c), userID, ifVersion)
+	if err != nil {
+		return err
+	}
+	setVersionETag(c, user.Version)
+	return utils.HandleSuccess(c, "Avatar removed successfully", responses.ToUserResponse(*user))
+}
+
+// ExportUserData godoc
+// @Summary Export personal data
+// @Description Download a JSON archive of the user's profile, sessions, identities and audit events
+// @Tags User
+// @Produce json
+// @Success 200 {object} responses.UserDataExport
+// @Failure 404 {object} utils.ProblemDetails
+// @Router /api/user/export [get]
+func (uc *UserController) ExportUserData(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	data, err := uc.accounts.ExportData(requestContext(c), userID)
+	if err != nil {
+		return err
+	}
+
+	export := responses.UserDataExport{
+		ExportedAt:  time.Now().UTC(),
+		Profile:     responses.ToUserResponse(data.User),
+		Sessions:    make([]responses.SessionExport, 0, len(data.Sessions)),
+		Identities:  data.Identities,
+		AuditEvents: data.AuditEvents,
+	}
+	for _, session := range data.Sessions {
+		export.Sessions = append(export.Sessions, responses.SessionExport{
+			ID:        session.ID.String(),
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+		})
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="user-%s-export.json"`, data.User.ID))
+	return c.JSON(export)
+}
+
+// DeleteAccount godoc
+// @Summary Delete own account
+// @Description Confirm with the current password; revokes all sessions and schedules the account for purging. Accounts without a password first call this without a token to get one by email, then call it again with the token.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param requests.DeleteAccountRequest body requests.DeleteAccountRequest true "Delete Account Request"
+// @Success 200 {object} map[string]interface{}
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} utils.ProblemDetails
+// @Failure 401 {object} utils.ProblemDetails
+// @Failure 422 {object} utils.ProblemDetails
+// @Router /api/user/delete [post]
+func (uc *UserController) DeleteAccount(c fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+	var req requests.DeleteAccountRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	// The JWT middleware already vetted the header; its token is blacklisted
+	// along with the account's other sessions.
+	token, _ := bearerToken(c)
+	deleted, err := uc.accounts.DeleteAccount(requestContext(c), userID, services.DeleteAccountInput{
+		Password:    req.Password,
+		Token:       req.Token,
+		AccessToken: token,
+	})
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		c.Status(fiber.StatusAccepted)
+		return utils.HandleSuccess(c, "Confirmation sent; repeat the request with the emailed token")
+	}
+	return utils.HandleSuccess(c, "Account deleted")
 }
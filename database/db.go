@@ -1,41 +1,239 @@
 package database
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/config"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
+// maxConnectBackoff caps the delay between connection attempts.
+const maxConnectBackoff = 30 * time.Second
 
-func ConnectDB() {
-	var dialector gorm.Dialector
-
-	if dsn := os.Getenv("DATABASE_URL"); dsn != "" {
-		// production / any server
-		dialector = postgres.Open(dsn)
-	} else {
+// ConnectDB opens the database selected by cfg.Driver(): SQLite at DBPath,
+// or Postgres, MySQL/MariaDB or SQL Server at DatabaseURL. It applies the pool
+// settings and retries with exponential backoff until the database answers a
+// ping or DBConnectRetries is exhausted. On a server database, reads are
+// spread over the DatabaseReplicaURLs when given; see WithPrimary.
+func ConnectDB(cfg config.Config) (*gorm.DB, error) {
+	driver := cfg.Driver()
+	if driver == config.DriverSQLite {
 		// local dev (SQLite)
-		dsn := os.Getenv("DB_PATH")
-		if dsn == "" {
-			dsn = "gofiber.db"
+		if len(cfg.DatabaseReplicaURLs) > 0 {
+			log.Printf("DATABASE_REPLICA_URLS is ignored with SQLite")
+		}
+		return connect(cfg, "database", sqlite.Open(sqliteDSN(cfg)))
+	}
+
+	// production / any server
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("DATABASE_URL is required with DB_DRIVER=%s", driver)
+	}
+	primary, err := serverDialector(cfg, driver, cfg.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+	db, err := connect(cfg, "database", primary)
+	if err != nil {
+		return nil, err
+	}
+	var replicas []*gorm.DB
+	for i, dsn := range cfg.DatabaseReplicaURLs {
+		dialector, err := serverDialector(cfg, driver, dsn)
+		if err != nil {
+			return nil, err
+		}
+		replica, err := connect(cfg, fmt.Sprintf("replica %d", i+1), dialector)
+		if err != nil {
+			return nil, err
 		}
-		dialector = sqlite.Open(dsn)
+		replicas = append(replicas, replica)
 	}
+	if err := UseReplicas(db, replicas...); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
 
+// serverDialector returns the dialector for a server driver and dsn.
+func serverDialector(cfg config.Config, driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case config.DriverPostgres:
+		return postgres.Open(postgresDSN(cfg, dsn)), nil
+	case config.DriverMySQL:
+		dsn, err := mysqlDSN(cfg, dsn)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.Open(dsn), nil
+	case config.DriverSQLServer:
+		return sqlserver.Open(sqlserverDSN(cfg, dsn)), nil
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q (want %s, %s, %s or %s)",
+			driver, config.DriverSQLite, config.DriverPostgres, config.DriverMySQL, config.DriverSQLServer)
+	}
+}
+
+// connect opens dialector, retrying with backoff. name labels log messages.
+func connect(cfg config.Config, name string, dialector gorm.Dialector) (*gorm.DB, error) {
+	backoff := time.Duration(cfg.DBConnectBackoffMS) * time.Millisecond
 	var err error
-	DB, err = gorm.Open(dialector, &gorm.Config{})
+	for attempt := 0; ; attempt++ {
+		var db *gorm.DB
+		if db, err = open(cfg, dialector); err == nil {
+			return db, nil
+		}
+		if attempt >= cfg.DBConnectRetries {
+			break
+		}
+		log.Printf("%s not ready (attempt %d of %d): %v; retrying in %s", name, attempt+1, cfg.DBConnectRetries+1, err, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+	return nil, fmt.Errorf("cannot connect to %s: %w", name, err)
+}
+
+func open(cfg config.Config, dialector gorm.Dialector) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: newLogger(cfg), TranslateError: true})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	if isSQLiteMemory(cfg) {
+		// An in-memory database lives only as long as one of its connections,
+		// so keep one open for good.
+		sqlDB.SetMaxIdleConns(max(cfg.DBMaxIdleConns, 1))
+	} else {
+		sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+		sqlDB.SetConnMaxLifetime(time.Duration(cfg.DBConnMaxLifetimeMin) * time.Minute)
+		sqlDB.SetConnMaxIdleTime(time.Duration(cfg.DBConnMaxIdleTimeMin) * time.Minute)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// postgresDSN adds the statement timeout to dsn, which may be a URL or a
+// key=value connection string.
+func postgresDSN(cfg config.Config, dsn string) string {
+	if cfg.DBStatementTimeoutMS <= 0 || strings.Contains(dsn, "statement_timeout") {
+		return dsn
+	}
+	timeout := strconv.Itoa(cfg.DBStatementTimeoutMS)
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("statement_timeout", timeout)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " statement_timeout=" + timeout
+}
+
+// mysqlDSN makes the driver scan DATETIME columns into time.Time, in UTC, and
+// use a four-byte charset. The statement timeout becomes the driver's
+// readTimeout, which MySQL and MariaDB both honour: max_execution_time is
+// MySQL-only and covers SELECTs alone.
+func mysqlDSN(cfg config.Config, dsn string) (string, error) {
+	parsed, err := mysqldriver.ParseDSN(dsn)
 	if err != nil {
-		log.Fatalf("cannot connect to database: %v", err)
+		return "", fmt.Errorf("invalid MySQL DATABASE_URL: %w", err)
+	}
+	parsed.ParseTime = true
+	if parsed.Params == nil {
+		parsed.Params = map[string]string{}
+	}
+	if _, ok := parsed.Params["charset"]; !ok {
+		parsed.Params["charset"] = "utf8mb4"
 	}
+	if cfg.DBStatementTimeoutMS > 0 && parsed.ReadTimeout == 0 {
+		parsed.ReadTimeout = time.Duration(cfg.DBStatementTimeoutMS) * time.Millisecond
+	}
+	return parsed.FormatDSN(), nil
+}
+
+// sqlserverDSN adds the statement timeout to dsn, a URL or an ADO connection
+// string, as the driver's "connection timeout". Past the login it bounds every
+// wait for the server, in whole seconds.
+func sqlserverDSN(cfg config.Config, dsn string) string {
+	if cfg.DBStatementTimeoutMS <= 0 || strings.Contains(strings.ToLower(dsn), "connection timeout") {
+		return dsn
+	}
+	timeout := strconv.Itoa((cfg.DBStatementTimeoutMS + 999) / 1000)
+	if u, err := url.Parse(dsn); err == nil && u.Scheme == "sqlserver" {
+		q := u.Query()
+		q.Set("connection timeout", timeout)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return strings.TrimSuffix(dsn, ";") + ";connection timeout=" + timeout
+}
+
+// sqliteDSN adds the journal mode and busy timeout pragmas to DBPath.
+func sqliteDSN(cfg config.Config) string {
+	dsn := cfg.DBPath
+	if dsn == "" {
+		dsn = "gofiber.db"
+	}
+
+	var params []string
+	if cfg.DBSQLiteWAL && !strings.Contains(dsn, "_journal_mode") {
+		params = append(params, "_journal_mode=WAL")
+	}
+	if cfg.DBSQLiteBusyTimeoutMS > 0 && !strings.Contains(dsn, "_busy_timeout") {
+		params = append(params, "_busy_timeout="+strconv.Itoa(cfg.DBSQLiteBusyTimeoutMS))
+	}
+	if len(params) == 0 {
+		return dsn
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + strings.Join(params, "&")
+}
+
+func isSQLiteMemory(cfg config.Config) bool {
+	return cfg.Driver() == config.DriverSQLite && (strings.Contains(cfg.DBPath, ":memory:") || strings.Contains(cfg.DBPath, "mode=memory"))
+}
 
-	if err := DB.AutoMigrate(
-		&models.User{},
-	); err != nil {
-		log.Fatalf("auto-migrate failed: %v", err)
+// newLogger logs queries slower than DBSlowQueryMS, and everything at or
+// above DBLogLevel (silent, error, warn or info).
+func newLogger(cfg config.Config) logger.Interface {
+	level := logger.Warn
+	switch cfg.DBLogLevel {
+	case "silent":
+		level = logger.Silent
+	case "error":
+		level = logger.Error
+	case "info":
+		level = logger.Info
 	}
+	return logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold:             time.Duration(cfg.DBSlowQueryMS) * time.Millisecond,
+		LogLevel:                  level,
+		IgnoreRecordNotFoundError: true,
+		Colorful:                  false,
+	})
 }
@@ -1,16 +1,229 @@
 package database
 
 import (
-	"github.com/ElvinEga/gofiber_starter/models"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-func MigrateDB() {
-	err := DB.AutoMigrate(
-		&models.User{},
-		&models.RefreshToken{},
-		// Add other models here
-	)
+// migrationFiles holds the SQL migrations, one directory per dialect. Files
+// are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration is a versioned schema change with SQL to apply and revert it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState is a migration and, when it has been applied, when.
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// schemaMigration is a row of the schema_migrations table.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrateDB applies every pending migration in version order. The first
+// migration creates only the tables, columns and indexes the former
+// AutoMigrate setup did, and skips tables that exist, so databases built by
+// it are adopted and then upgraded like any other. Each migration
+// runs in its own transaction together with its schema_migrations row; MySQL
+// commits DDL implicitly, so there a failed migration may be half applied.
+func MigrateDB(db *gorm.DB) error {
+	db = onPrimary(db)
+	migrations, applied, err := loadMigrationState(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, m.Up); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// RollbackDB reverts the latest steps applied migrations, newest first.
+func RollbackDB(db *gorm.DB, steps int) error {
+	db = onPrimary(db)
+	migrations, applied, err := loadMigrationState(db)
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return fmt.Errorf("migration %04d_%s cannot be rolled back: no down script", m.Version, m.Name)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, m.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{Version: m.Version}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		steps--
+	}
+	return nil
+}
+
+// MigrationStatus lists every known migration with the time it was applied,
+// nil for pending ones.
+func MigrationStatus(db *gorm.DB) ([]MigrationState, error) {
+	db = onPrimary(db)
+	migrations, applied, err := loadMigrationState(db)
 	if err != nil {
-		panic("Failed to migrate database")
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Migration: m}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// onPrimary makes db read from the primary, since migration state must never
+// come from a lagging replica.
+func onPrimary(db *gorm.DB) *gorm.DB {
+	return db.WithContext(WithPrimary(context.Background()))
+}
+
+// loadMigrationState ensures the schema_migrations table exists and returns
+// the migrations for db's dialect along with the applied rows by version.
+func loadMigrationState(db *gorm.DB) ([]Migration, map[int]schemaMigration, error) {
+	migrations, err := LoadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, nil, fmt.Errorf("cannot create schema_migrations: %w", err)
+	}
+
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("cannot read schema_migrations: %w", err)
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return migrations, applied, nil
+}
+
+// LoadMigrations reads the embedded migrations for a GORM dialect name
+// ("sqlite", "postgres", "mysql" or "sqlserver"), sorted by version.
+func LoadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		prefix, label, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		body, err := fs.ReadFile(migrationFiles, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration version %d is used by %q and %q", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// execStatements runs a script one statement at a time, since not every
+// driver accepts several statements in a single Exec.
+func execStatements(tx *gorm.DB, script string) error {
+	for _, stmt := range strings.Split(script, ";") {
+		if stmt = strings.TrimSpace(stmt); stmt == "" {
+			continue
+		}
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255),
+    username VARCHAR(255),
+    email VARCHAR(255),
+    password VARCHAR(255),
+    role VARCHAR(32),
+    is_verified BOOLEAN,
+    email_verified_at DATETIME(3),
+    verification_token VARCHAR(255),
+    reset_token VARCHAR(255),
+    reset_expires_at DATETIME(3),
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    deleted_at DATETIME(3),
+    UNIQUE INDEX idx_users_email (email),
+    UNIQUE INDEX idx_users_username (username)
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36),
+    token VARCHAR(512),
+    expires_at DATETIME(3),
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    deleted_at DATETIME(3),
+    UNIQUE INDEX idx_refresh_tokens_token (token),
+    INDEX idx_refresh_tokens_user_id (user_id)
+) DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS invitations (
+    id VARCHAR(36) PRIMARY KEY,
+    code VARCHAR(255),
+    email VARCHAR(255),
+    created_by VARCHAR(36),
+    expires_at DATETIME(3),
+    used_at DATETIME(3),
+    used_by VARCHAR(36),
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    UNIQUE INDEX idx_invitations_code (code),
+    INDEX idx_invitations_email (email)
+) DEFAULT CHARSET = utf8mb4;
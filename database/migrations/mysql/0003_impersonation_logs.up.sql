@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS impersonation_logs (
+    id VARCHAR(36) PRIMARY KEY,
+    impersonator_id VARCHAR(36),
+    target_user_id VARCHAR(36),
+    reason TEXT,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    expires_at DATETIME(3),
+    created_at DATETIME(3),
+    INDEX idx_impersonation_logs_impersonator_id (impersonator_id),
+    INDEX idx_impersonation_logs_target_user_id (target_user_id)
+) DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id VARCHAR(36) PRIMARY KEY,
+    action VARCHAR(64),
+    outcome VARCHAR(32),
+    actor_id VARCHAR(36),
+    target_id VARCHAR(36),
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    metadata TEXT,
+    created_at DATETIME(3),
+    INDEX idx_audit_events_action (action),
+    INDEX idx_audit_events_outcome (outcome),
+    INDEX idx_audit_events_actor_id (actor_id),
+    INDEX idx_audit_events_target_id (target_id),
+    INDEX idx_audit_events_created_at (created_at)
+) DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS user_identities (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36),
+    provider VARCHAR(32),
+    subject VARCHAR(255),
+    email VARCHAR(255),
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    UNIQUE INDEX idx_identity_provider_subject (provider, subject),
+    INDEX idx_user_identities_user_id (user_id)
+) DEFAULT CHARSET = utf8mb4;
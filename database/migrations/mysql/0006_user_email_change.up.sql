@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN pending_email VARCHAR(255);
+ALTER TABLE users ADD COLUMN email_change_token VARCHAR(255);
+ALTER TABLE users ADD COLUMN email_change_expires_at DATETIME(3);
+CREATE INDEX idx_users_email_change_token ON users (email_change_token);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS password_histories (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36),
+    hash VARCHAR(255),
+    created_at DATETIME(3),
+    INDEX idx_password_histories_user_id (user_id),
+    INDEX idx_password_histories_created_at (created_at)
+) DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN avatar_url VARCHAR(2048);
+ALTER TABLE users ADD COLUMN bio TEXT;
+ALTER TABLE users ADD COLUMN locale VARCHAR(35);
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64);
+ALTER TABLE users ADD COLUMN phone VARCHAR(16);
+ALTER TABLE users ADD COLUMN metadata TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN avatar_thumbnail_url VARCHAR(2048);
+ALTER TABLE users ADD COLUMN avatar_key VARCHAR(255);
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN deletion_token VARCHAR(255);
+ALTER TABLE users ADD COLUMN deletion_expires_at DATETIME(3);
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN email_change_auth_token VARCHAR(255);
+ALTER TABLE users ADD COLUMN email_change_auth_expires_at DATETIME(3);
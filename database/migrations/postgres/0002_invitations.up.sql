@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS invitations (
+    id TEXT PRIMARY KEY,
+    code TEXT,
+    email TEXT,
+    created_by TEXT,
+    expires_at TIMESTAMPTZ,
+    used_at TIMESTAMPTZ,
+    used_by TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_code ON invitations (code);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations (email);
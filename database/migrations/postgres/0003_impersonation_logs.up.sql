@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS impersonation_logs (
+    id TEXT PRIMARY KEY,
+    impersonator_id TEXT,
+    target_user_id TEXT,
+    reason TEXT,
+    ip_address TEXT,
+    user_agent TEXT,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_impersonation_logs_impersonator_id ON impersonation_logs (impersonator_id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_logs_target_user_id ON impersonation_logs (target_user_id);
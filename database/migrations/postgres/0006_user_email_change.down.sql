@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_email_change_token;
+ALTER TABLE users DROP COLUMN email_change_expires_at;
+ALTER TABLE users DROP COLUMN email_change_token;
+ALTER TABLE users DROP COLUMN pending_email;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN pending_email TEXT;
+ALTER TABLE users ADD COLUMN email_change_token TEXT;
+ALTER TABLE users ADD COLUMN email_change_expires_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_users_email_change_token ON users (email_change_token);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS password_histories (
+    id TEXT PRIMARY KEY,
+    user_id TEXT,
+    hash TEXT,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_password_histories_user_id ON password_histories (user_id);
+CREATE INDEX IF NOT EXISTS idx_password_histories_created_at ON password_histories (created_at);
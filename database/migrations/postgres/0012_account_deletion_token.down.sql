@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN deletion_expires_at;
+ALTER TABLE users DROP COLUMN deletion_token;
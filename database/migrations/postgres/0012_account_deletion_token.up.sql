@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN deletion_token TEXT;
+ALTER TABLE users ADD COLUMN deletion_expires_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN email_change_auth_token TEXT;
+ALTER TABLE users ADD COLUMN email_change_auth_expires_at TIMESTAMPTZ;
@@ -0,0 +1,6 @@
+ALTER TABLE users DROP COLUMN metadata;
+ALTER TABLE users DROP COLUMN phone;
+ALTER TABLE users DROP COLUMN timezone;
+ALTER TABLE users DROP COLUMN locale;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN avatar_url;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN avatar_thumbnail_url TEXT;
+ALTER TABLE users ADD COLUMN avatar_key TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN email_change_auth_expires_at;
+ALTER TABLE users DROP COLUMN email_change_auth_token;
@@ -0,0 +1,32 @@
+IF OBJECT_ID(N'users', N'U') IS NULL
+CREATE TABLE users (
+    id NVARCHAR(36) PRIMARY KEY,
+    name NVARCHAR(255),
+    username NVARCHAR(255),
+    email NVARCHAR(255),
+    password NVARCHAR(255),
+    role NVARCHAR(32),
+    is_verified BIT,
+    email_verified_at DATETIMEOFFSET,
+    verification_token NVARCHAR(255),
+    reset_token NVARCHAR(255),
+    reset_expires_at DATETIMEOFFSET,
+    created_at DATETIMEOFFSET,
+    updated_at DATETIMEOFFSET,
+    deleted_at DATETIMEOFFSET,
+    INDEX idx_users_email UNIQUE (email),
+    INDEX idx_users_username UNIQUE (username)
+);
+
+IF OBJECT_ID(N'refresh_tokens', N'U') IS NULL
+CREATE TABLE refresh_tokens (
+    id NVARCHAR(36) PRIMARY KEY,
+    user_id NVARCHAR(36),
+    token NVARCHAR(512),
+    expires_at DATETIMEOFFSET,
+    created_at DATETIMEOFFSET,
+    updated_at DATETIMEOFFSET,
+    deleted_at DATETIMEOFFSET,
+    INDEX idx_refresh_tokens_token UNIQUE (token),
+    INDEX idx_refresh_tokens_user_id (user_id)
+);
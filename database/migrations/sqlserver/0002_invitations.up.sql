@@ -0,0 +1,14 @@
+IF OBJECT_ID(N'invitations', N'U') IS NULL
+CREATE TABLE invitations (
+    id NVARCHAR(36) PRIMARY KEY,
+    code NVARCHAR(255),
+    email NVARCHAR(255),
+    created_by NVARCHAR(36),
+    expires_at DATETIMEOFFSET,
+    used_at DATETIMEOFFSET,
+    used_by NVARCHAR(36),
+    created_at DATETIMEOFFSET,
+    updated_at DATETIMEOFFSET,
+    INDEX idx_invitations_code UNIQUE (code),
+    INDEX idx_invitations_email (email)
+);
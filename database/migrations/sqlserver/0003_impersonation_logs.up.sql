@@ -0,0 +1,13 @@
+IF OBJECT_ID(N'impersonation_logs', N'U') IS NULL
+CREATE TABLE impersonation_logs (
+    id NVARCHAR(36) PRIMARY KEY,
+    impersonator_id NVARCHAR(36),
+    target_user_id NVARCHAR(36),
+    reason NVARCHAR(MAX),
+    ip_address NVARCHAR(64),
+    user_agent NVARCHAR(MAX),
+    expires_at DATETIMEOFFSET,
+    created_at DATETIMEOFFSET,
+    INDEX idx_impersonation_logs_impersonator_id (impersonator_id),
+    INDEX idx_impersonation_logs_target_user_id (target_user_id)
+);
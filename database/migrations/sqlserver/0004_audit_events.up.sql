@@ -0,0 +1,17 @@
+IF OBJECT_ID(N'audit_events', N'U') IS NULL
+CREATE TABLE audit_events (
+    id NVARCHAR(36) PRIMARY KEY,
+    action NVARCHAR(64),
+    outcome NVARCHAR(32),
+    actor_id NVARCHAR(36),
+    target_id NVARCHAR(36),
+    ip_address NVARCHAR(64),
+    user_agent NVARCHAR(MAX),
+    metadata NVARCHAR(MAX),
+    created_at DATETIMEOFFSET,
+    INDEX idx_audit_events_action (action),
+    INDEX idx_audit_events_outcome (outcome),
+    INDEX idx_audit_events_actor_id (actor_id),
+    INDEX idx_audit_events_target_id (target_id),
+    INDEX idx_audit_events_created_at (created_at)
+);
@@ -0,0 +1,12 @@
+IF OBJECT_ID(N'user_identities', N'U') IS NULL
+CREATE TABLE user_identities (
+    id NVARCHAR(36) PRIMARY KEY,
+    user_id NVARCHAR(36),
+    provider NVARCHAR(32),
+    subject NVARCHAR(255),
+    email NVARCHAR(255),
+    created_at DATETIMEOFFSET,
+    updated_at DATETIMEOFFSET,
+    INDEX idx_identity_provider_subject UNIQUE (provider, subject),
+    INDEX idx_user_identities_user_id (user_id)
+);
@@ -0,0 +1,2 @@
+DROP INDEX idx_users_email_change_token ON users;
+ALTER TABLE users DROP COLUMN email_change_expires_at, email_change_token, pending_email;
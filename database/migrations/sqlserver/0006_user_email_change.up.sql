@@ -0,0 +1,4 @@
+ALTER TABLE users ADD pending_email NVARCHAR(255);
+ALTER TABLE users ADD email_change_token NVARCHAR(255);
+ALTER TABLE users ADD email_change_expires_at DATETIMEOFFSET;
+CREATE INDEX idx_users_email_change_token ON users (email_change_token);
@@ -0,0 +1,9 @@
+IF OBJECT_ID(N'password_histories', N'U') IS NULL
+CREATE TABLE password_histories (
+    id NVARCHAR(36) PRIMARY KEY,
+    user_id NVARCHAR(36),
+    hash NVARCHAR(255),
+    created_at DATETIMEOFFSET,
+    INDEX idx_password_histories_user_id (user_id),
+    INDEX idx_password_histories_created_at (created_at)
+);
@@ -0,0 +1 @@
+ALTER TABLE users ADD version BIGINT NOT NULL CONSTRAINT df_users_version DEFAULT 1;
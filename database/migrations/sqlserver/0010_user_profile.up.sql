@@ -0,0 +1,6 @@
+ALTER TABLE users ADD avatar_url NVARCHAR(2048);
+ALTER TABLE users ADD bio NVARCHAR(MAX);
+ALTER TABLE users ADD locale NVARCHAR(35);
+ALTER TABLE users ADD timezone NVARCHAR(64);
+ALTER TABLE users ADD phone NVARCHAR(16);
+ALTER TABLE users ADD metadata NVARCHAR(MAX);
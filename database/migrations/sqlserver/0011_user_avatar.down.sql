@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN avatar_key, avatar_thumbnail_url;
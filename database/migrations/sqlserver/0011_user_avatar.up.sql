@@ -0,0 +1,2 @@
+ALTER TABLE users ADD avatar_thumbnail_url NVARCHAR(2048);
+ALTER TABLE users ADD avatar_key NVARCHAR(255);
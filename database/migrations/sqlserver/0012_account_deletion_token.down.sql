@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN deletion_expires_at, deletion_token;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD deletion_token NVARCHAR(255);
+ALTER TABLE users ADD deletion_expires_at DATETIMEOFFSET;
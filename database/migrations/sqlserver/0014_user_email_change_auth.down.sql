@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN email_change_auth_expires_at, email_change_auth_token;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD email_change_auth_token NVARCHAR(255);
+ALTER TABLE users ADD email_change_auth_expires_at DATETIMEOFFSET;
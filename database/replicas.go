@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+type primaryKey struct{}
+
+// WithPrimary returns a copy of ctx whose queries read from the primary even
+// when replicas are configured. Use it where a read must see a write made
+// just before, such as a uniqueness check or a token issued a moment ago.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// usesPrimary reports whether ctx was made by WithPrimary.
+func usesPrimary(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+// replicaResolver is a GORM plugin that sends reads to replicas in turn.
+// Writes, Exec statements, locking reads, transactions and queries made with
+// a WithPrimary context stay on the primary.
+type replicaResolver struct {
+	replicas []gorm.ConnPool
+	next     atomic.Uint64
+}
+
+func (r *replicaResolver) Name() string {
+	return "replica_resolver"
+}
+
+func (r *replicaResolver) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("replicas:query", r.route); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("replicas:row", r.route)
+}
+
+func (r *replicaResolver) route(db *gorm.DB) {
+	stmt := db.Statement
+	if _, inTx := stmt.ConnPool.(gorm.TxCommitter); inTx {
+		return
+	}
+	if _, locking := stmt.Clauses["FOR"]; locking {
+		return
+	}
+	if stmt.Context != nil && usesPrimary(stmt.Context) {
+		return
+	}
+	n := r.next.Add(1)
+	stmt.ConnPool = r.replicas[n%uint64(len(r.replicas))]
+}
+
+// UseReplicas routes db's reads to the given replica connections. The
+// replicas must hold a copy of db's data; their own plugins and callbacks are
+// not used.
+func UseReplicas(db *gorm.DB, replicas ...*gorm.DB) error {
+	if len(replicas) == 0 {
+		return nil
+	}
+	resolver := &replicaResolver{}
+	for _, replica := range replicas {
+		pool, err := replica.DB()
+		if err != nil {
+			return err
+		}
+		resolver.replicas = append(resolver.replicas, pool)
+	}
+	if _, ok := db.Config.Plugins[resolver.Name()]; ok {
+		return errors.New("replicas are already configured")
+	}
+	return db.Use(resolver)
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// WithTx runs fn in a transaction on db, bound to ctx. The transaction is
+// committed when fn returns nil and rolled back when fn returns an error or
+// panics; a failed commit is returned as the error.
+//
+// When db is already a transaction, fn runs in it rather than in a nested
+// savepoint, so a helper can use WithTx whether or not its caller began a
+// transaction, and any error rolls back the whole unit of work.
+func WithTx(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	db = db.WithContext(ctx)
+	if InTx(db) {
+		return fn(db)
+	}
+	return db.Transaction(fn)
+}
+
+// InTx reports whether db is a transaction.
+func InTx(db *gorm.DB) bool {
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
@@ -0,0 +1,100 @@
+package i18n
+
+var english = Catalog{
+	ErrInternal:                "An unexpected error occurred",
+	ErrBadRequest:              "Bad request",
+	ErrNotFound:                "Resource not found",
+	ErrMethodNotAllowed:        "Method not allowed",
+	ErrRequestTooLarge:         "Request body too large",
+	ErrInvalidPayload:          "Invalid payload",
+	ErrValidationFailed:        "Validation failed",
+	ErrUnauthorized:            "Unauthorized",
+	ErrForbidden:               "Forbidden",
+	ErrTooManyRequests:         "Too many requests",
+	ErrDatabase:                "Database error",
+	ErrUserNotFound:            "User not found",
+	ErrInvalidUserID:           "Invalid user ID",
+	ErrInvalidFilter:           "Invalid filter value",
+	ErrUserCreateFailed:        "Could not create user",
+	ErrTokenGenerationFailed:   "Could not generate tokens",
+	ErrPasswordHashFailed:      "Could not hash password",
+	ErrPasswordUpdateFailed:    "Could not update password",
+	ErrPasswordResetFailed:     "Could not reset password",
+	ErrRoleUpdateFailed:        "Could not update role",
+	ErrEmailSendFailed:         "Could not send email",
+	ErrEmailChangeFailed:       "Could not change email",
+	ErrAccountDeleteFailed:     "Could not delete account",
+	ErrInvitationCreateFailed:  "Could not create invitation",
+	ErrInvitationRedeemFailed:  "Could not redeem invitation",
+	ErrImpersonationLogFailed:  "Could not record impersonation",
+	ErrInvalidCredentials:      "Invalid credentials",
+	ErrIncorrectPassword:       "Incorrect password",
+	ErrIncorrectCurrentPass:    "Incorrect current password",
+	ErrInvalidRefreshToken:     "Invalid or expired refresh token",
+	ErrInvalidResetToken:       "Invalid or expired reset token",
+	ErrVerificationTokenNeeded: "Verification token is required",
+	ErrInvalidVerification:     "Invalid verification token",
+	ErrConfirmationTokenNeeded: "Confirmation token is required",
+	ErrInvalidConfirmation:     "Invalid or expired confirmation token",
+	ErrMissingAuthHeader:       "Authorization header not found",
+	ErrInvalidAuthHeader:       "Invalid authorization header",
+	ErrInvalidToken:            "Invalid token",
+	ErrEmailExists:             "Email already exists",
+	ErrUsernameTaken:           "Username already taken",
+	ErrEmailUnchanged:          "New email must differ from the current one",
+	ErrPasswordPolicy:          "Password does not meet the password policy",
+	ErrRegistrationClosed:      "Registration is closed",
+	ErrInvitationRequired:      "An invitation is required to register",
+	ErrInvitationInvalid:       "Invitation is invalid, expired or already used",
+	ErrEmailDomainNotAllowed:   "Email domain is not allowed",
+	ErrDisposableEmail:         "Disposable email addresses are not allowed",
+	ErrImpersonationRestricted: "This action is not allowed while impersonating a user",
+	ErrImpersonationNested:     "Cannot impersonate while impersonating",
+	ErrImpersonationSelf:       "Cannot impersonate yourself",
+	ErrImpersonationSuperadmin: "Cannot impersonate a superadmin",
+	ErrRoleChangeSelf:          "Cannot change your own role",
+	ErrInvalidRole:             "Unknown role",
+	ErrLastSuperadmin:          "Cannot demote the last superadmin",
+	ErrInvalidSetupToken:       "Invalid setup token",
+	ErrSetupUnavailable:        "Initial setup has already been completed",
+	ErrPasswordChangeRequired:  "You must change your password before continuing",
+	ErrOAuthCodeMissing:        "Authorization code not found",
+	ErrOAuthFailed:             "Could not complete sign-in with the provider",
+	ErrAccountDeleted:          "Account has been deleted",
+	ErrIdentityLinkFailed:      "Could not link identity",
+	ErrVersionConflict:         "The resource was changed by another request; reload it and try again",
+	ErrPreconditionFailed:      "The resource has changed since it was read (If-Match does not match)",
+	ErrUnsupportedMediaType:    "Unsupported content type",
+	ErrInvalidPatch:            "The patch document is malformed or cannot be applied",
+	ErrPatchTestFailed:         "A test operation in the patch did not match the resource",
+	ErrFileRequired:            "A file is required",
+	ErrFileTooLarge:            "The file is too large",
+	ErrUnsupportedFileType:     "This type of file is not accepted",
+	ErrInvalidImage:            "The file is not a valid image or is too large to process",
+	ErrUploadFailed:            "Could not store the file",
+
+	ValidationRequired:  "This field is required",
+	ValidationEmail:     "Must be a valid email address",
+	ValidationMinLength: "Must be at least {min} characters long",
+	ValidationMaxLength: "Must be at most {max} characters long",
+	ValidationMin:       "Must be at least {min}",
+	ValidationMax:       "Must be at most {max}",
+	ValidationOneOf:     "Must be one of: {options}",
+	ValidationUUID:      "Must be a valid UUID",
+	ValidationRegex:     "Has an invalid format",
+	ValidationEqField:   "Must match {field}",
+	ValidationInvalid:   "Is invalid",
+	ValidationReadOnly:  "Cannot be changed",
+	ValidationURL:       "Must be an http or https URL",
+	ValidationTimezone:  "Must be an IANA time zone such as Europe/Paris",
+
+	PasswordTooShort:      "Must be at least {min} characters long",
+	PasswordTooLong:       "Must be at most {max} bytes long",
+	PasswordMissingUpper:  "Must contain an uppercase letter",
+	PasswordMissingLower:  "Must contain a lowercase letter",
+	PasswordMissingDigit:  "Must contain a digit",
+	PasswordMissingSymbol: "Must contain a symbol",
+	PasswordPersonalInfo:  "Must not contain your name or email address",
+	PasswordBreached:      "This password has appeared in a data breach; choose another one",
+	PasswordReused:        "Must not match one of your recent passwords",
+}
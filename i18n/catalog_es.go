@@ -0,0 +1,100 @@
+package i18n
+
+var spanish = Catalog{
+	ErrInternal:                "Se ha producido un error inesperado",
+	ErrBadRequest:              "Solicitud incorrecta",
+	ErrNotFound:                "Recurso no encontrado",
+	ErrMethodNotAllowed:        "Método no permitido",
+	ErrRequestTooLarge:         "El cuerpo de la solicitud es demasiado grande",
+	ErrInvalidPayload:          "Solicitud no válida",
+	ErrValidationFailed:        "La validación ha fallado",
+	ErrUnauthorized:            "No autorizado",
+	ErrForbidden:               "Prohibido",
+	ErrTooManyRequests:         "Demasiadas solicitudes",
+	ErrDatabase:                "Error de base de datos",
+	ErrUserNotFound:            "Usuario no encontrado",
+	ErrInvalidUserID:           "ID de usuario no válido",
+	ErrInvalidFilter:           "Valor de filtro no válido",
+	ErrUserCreateFailed:        "No se pudo crear el usuario",
+	ErrTokenGenerationFailed:   "No se pudieron generar los tokens",
+	ErrPasswordHashFailed:      "No se pudo cifrar la contraseña",
+	ErrPasswordUpdateFailed:    "No se pudo actualizar la contraseña",
+	ErrPasswordResetFailed:     "No se pudo restablecer la contraseña",
+	ErrRoleUpdateFailed:        "No se pudo actualizar el rol",
+	ErrEmailSendFailed:         "No se pudo enviar el correo",
+	ErrEmailChangeFailed:       "No se pudo cambiar el correo electrónico",
+	ErrAccountDeleteFailed:     "No se pudo eliminar la cuenta",
+	ErrInvitationCreateFailed:  "No se pudo crear la invitación",
+	ErrInvitationRedeemFailed:  "No se pudo canjear la invitación",
+	ErrImpersonationLogFailed:  "No se pudo registrar la suplantación",
+	ErrInvalidCredentials:      "Credenciales no válidas",
+	ErrIncorrectPassword:       "Contraseña incorrecta",
+	ErrIncorrectCurrentPass:    "La contraseña actual es incorrecta",
+	ErrInvalidRefreshToken:     "Token de actualización no válido o caducado",
+	ErrInvalidResetToken:       "Token de restablecimiento no válido o caducado",
+	ErrVerificationTokenNeeded: "Se requiere el token de verificación",
+	ErrInvalidVerification:     "Token de verificación no válido",
+	ErrConfirmationTokenNeeded: "Se requiere el token de confirmación",
+	ErrInvalidConfirmation:     "Token de confirmación no válido o caducado",
+	ErrMissingAuthHeader:       "No se encontró la cabecera de autorización",
+	ErrInvalidAuthHeader:       "Cabecera de autorización no válida",
+	ErrInvalidToken:            "Token no válido",
+	ErrEmailExists:             "El correo electrónico ya existe",
+	ErrUsernameTaken:           "El nombre de usuario ya está en uso",
+	ErrEmailUnchanged:          "El nuevo correo debe ser distinto del actual",
+	ErrPasswordPolicy:          "La contraseña no cumple la política de contraseñas",
+	ErrRegistrationClosed:      "El registro está cerrado",
+	ErrInvitationRequired:      "Se requiere una invitación para registrarse",
+	ErrInvitationInvalid:       "La invitación no es válida, ha caducado o ya se ha usado",
+	ErrEmailDomainNotAllowed:   "El dominio del correo no está permitido",
+	ErrDisposableEmail:         "No se permiten direcciones de correo desechables",
+	ErrImpersonationRestricted: "Esta acción no está permitida mientras se suplanta a un usuario",
+	ErrImpersonationNested:     "No se puede suplantar durante una suplantación",
+	ErrImpersonationSelf:       "No puedes suplantarte a ti mismo",
+	ErrImpersonationSuperadmin: "No se puede suplantar a un superadministrador",
+	ErrRoleChangeSelf:          "No puedes cambiar tu propio rol",
+	ErrInvalidRole:             "Rol desconocido",
+	ErrLastSuperadmin:          "No se puede degradar al último superadministrador",
+	ErrInvalidSetupToken:       "Token de configuración no válido",
+	ErrSetupUnavailable:        "La configuración inicial ya se ha completado",
+	ErrPasswordChangeRequired:  "Debes cambiar tu contraseña antes de continuar",
+	ErrOAuthCodeMissing:        "No se encontró el código de autorización",
+	ErrOAuthFailed:             "No se pudo completar el inicio de sesión con el proveedor",
+	ErrAccountDeleted:          "La cuenta ha sido eliminada",
+	ErrIdentityLinkFailed:      "No se pudo vincular la identidad",
+	ErrVersionConflict:         "Otra solicitud modificó el recurso; vuelve a cargarlo e inténtalo de nuevo",
+	ErrPreconditionFailed:      "El recurso ha cambiado desde que se leyó (If-Match no coincide)",
+	ErrUnsupportedMediaType:    "Tipo de contenido no admitido",
+	ErrInvalidPatch:            "El documento de parche no es válido o no se puede aplicar",
+	ErrPatchTestFailed:         "Una operación test del parche no coincide con el recurso",
+	ErrFileRequired:            "Se requiere un archivo",
+	ErrFileTooLarge:            "El archivo es demasiado grande",
+	ErrUnsupportedFileType:     "No se acepta este tipo de archivo",
+	ErrInvalidImage:            "El archivo no es una imagen válida o es demasiado grande para procesarla",
+	ErrUploadFailed:            "No se pudo guardar el archivo",
+
+	ValidationRequired:  "Este campo es obligatorio",
+	ValidationEmail:     "Debe ser una dirección de correo válida",
+	ValidationMinLength: "Debe tener al menos {min} caracteres",
+	ValidationMaxLength: "Debe tener como máximo {max} caracteres",
+	ValidationMin:       "Debe ser al menos {min}",
+	ValidationMax:       "Debe ser como máximo {max}",
+	ValidationOneOf:     "Debe ser uno de: {options}",
+	ValidationUUID:      "Debe ser un UUID válido",
+	ValidationRegex:     "Tiene un formato no válido",
+	ValidationEqField:   "Debe coincidir con {field}",
+	ValidationInvalid:   "No es válido",
+	ValidationReadOnly:  "No se puede modificar",
+	ValidationURL:       "Debe ser una URL http o https",
+	ValidationTimezone:  "Debe ser una zona horaria IANA como Europe/Madrid",
+
+	PasswordTooShort:      "Debe tener al menos {min} caracteres",
+	PasswordTooLong:       "Debe tener como máximo {max} bytes",
+	PasswordMissingUpper:  "Debe contener una letra mayúscula",
+	PasswordMissingLower:  "Debe contener una letra minúscula",
+	PasswordMissingDigit:  "Debe contener un dígito",
+	PasswordMissingSymbol: "Debe contener un símbolo",
+	PasswordPersonalInfo:  "No debe contener tu nombre ni tu correo electrónico",
+	PasswordBreached:      "Esta contraseña ha aparecido en una filtración de datos; elige otra",
+	PasswordReused:        "No debe coincidir con ninguna de tus contraseñas recientes",
+}
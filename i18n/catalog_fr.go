@@ -0,0 +1,100 @@
+package i18n
+
+var french = Catalog{
+	ErrInternal:                "Une erreur inattendue est survenue",
+	ErrBadRequest:              "Requête incorrecte",
+	ErrNotFound:                "Ressource introuvable",
+	ErrMethodNotAllowed:        "Méthode non autorisée",
+	ErrRequestTooLarge:         "Le corps de la requête est trop volumineux",
+	ErrInvalidPayload:          "Requête invalide",
+	ErrValidationFailed:        "La validation a échoué",
+	ErrUnauthorized:            "Non autorisé",
+	ErrForbidden:               "Interdit",
+	ErrTooManyRequests:         "Trop de requêtes",
+	ErrDatabase:                "Erreur de base de données",
+	ErrUserNotFound:            "Utilisateur introuvable",
+	ErrInvalidUserID:           "Identifiant utilisateur invalide",
+	ErrInvalidFilter:           "Valeur de filtre invalide",
+	ErrUserCreateFailed:        "Impossible de créer l'utilisateur",
+	ErrTokenGenerationFailed:   "Impossible de générer les jetons",
+	ErrPasswordHashFailed:      "Impossible de hacher le mot de passe",
+	ErrPasswordUpdateFailed:    "Impossible de mettre à jour le mot de passe",
+	ErrPasswordResetFailed:     "Impossible de réinitialiser le mot de passe",
+	ErrRoleUpdateFailed:        "Impossible de mettre à jour le rôle",
+	ErrEmailSendFailed:         "Impossible d'envoyer l'e-mail",
+	ErrEmailChangeFailed:       "Impossible de changer l'adresse e-mail",
+	ErrAccountDeleteFailed:     "Impossible de supprimer le compte",
+	ErrInvitationCreateFailed:  "Impossible de créer l'invitation",
+	ErrInvitationRedeemFailed:  "Impossible d'utiliser l'invitation",
+	ErrImpersonationLogFailed:  "Impossible d'enregistrer l'usurpation d'identité",
+	ErrInvalidCredentials:      "Identifiants invalides",
+	ErrIncorrectPassword:       "Mot de passe incorrect",
+	ErrIncorrectCurrentPass:    "Le mot de passe actuel est incorrect",
+	ErrInvalidRefreshToken:     "Jeton de rafraîchissement invalide ou expiré",
+	ErrInvalidResetToken:       "Jeton de réinitialisation invalide ou expiré",
+	ErrVerificationTokenNeeded: "Le jeton de vérification est requis",
+	ErrInvalidVerification:     "Jeton de vérification invalide",
+	ErrConfirmationTokenNeeded: "Le jeton de confirmation est requis",
+	ErrInvalidConfirmation:     "Jeton de confirmation invalide ou expiré",
+	ErrMissingAuthHeader:       "En-tête d'autorisation introuvable",
+	ErrInvalidAuthHeader:       "En-tête d'autorisation invalide",
+	ErrInvalidToken:            "Jeton invalide",
+	ErrEmailExists:             "L'adresse e-mail existe déjà",
+	ErrUsernameTaken:           "Ce nom d'utilisateur est déjà pris",
+	ErrEmailUnchanged:          "La nouvelle adresse doit être différente de l'actuelle",
+	ErrPasswordPolicy:          "Le mot de passe ne respecte pas la politique de mots de passe",
+	ErrRegistrationClosed:      "Les inscriptions sont fermées",
+	ErrInvitationRequired:      "Une invitation est requise pour s'inscrire",
+	ErrInvitationInvalid:       "L'invitation est invalide, expirée ou déjà utilisée",
+	ErrEmailDomainNotAllowed:   "Ce domaine de messagerie n'est pas autorisé",
+	ErrDisposableEmail:         "Les adresses e-mail jetables ne sont pas autorisées",
+	ErrImpersonationRestricted: "Cette action est interdite pendant l'usurpation d'un utilisateur",
+	ErrImpersonationNested:     "Impossible d'usurper une identité pendant une usurpation",
+	ErrImpersonationSelf:       "Vous ne pouvez pas usurper votre propre identité",
+	ErrImpersonationSuperadmin: "Impossible d'usurper l'identité d'un superadministrateur",
+	ErrRoleChangeSelf:          "Vous ne pouvez pas modifier votre propre rôle",
+	ErrInvalidRole:             "Rôle inconnu",
+	ErrLastSuperadmin:          "Impossible de rétrograder le dernier superadministrateur",
+	ErrInvalidSetupToken:       "Jeton de configuration invalide",
+	ErrSetupUnavailable:        "La configuration initiale a déjà été effectuée",
+	ErrPasswordChangeRequired:  "Vous devez changer votre mot de passe avant de continuer",
+	ErrOAuthCodeMissing:        "Code d'autorisation introuvable",
+	ErrOAuthFailed:             "Impossible de finaliser la connexion avec le fournisseur",
+	ErrAccountDeleted:          "Le compte a été supprimé",
+	ErrIdentityLinkFailed:      "Impossible d'associer l'identité",
+	ErrVersionConflict:         "La ressource a été modifiée par une autre requête ; rechargez-la et réessayez",
+	ErrPreconditionFailed:      "La ressource a changé depuis sa lecture (If-Match ne correspond pas)",
+	ErrUnsupportedMediaType:    "Type de contenu non pris en charge",
+	ErrInvalidPatch:            "Le document de correctif est invalide ou ne peut pas être appliqué",
+	ErrPatchTestFailed:         "Une opération test du correctif ne correspond pas à la ressource",
+	ErrFileRequired:            "Un fichier est requis",
+	ErrFileTooLarge:            "Le fichier est trop volumineux",
+	ErrUnsupportedFileType:     "Ce type de fichier n'est pas accepté",
+	ErrInvalidImage:            "Le fichier n'est pas une image valide ou est trop grand pour être traité",
+	ErrUploadFailed:            "Impossible d'enregistrer le fichier",
+
+	ValidationRequired:  "Ce champ est obligatoire",
+	ValidationEmail:     "Doit être une adresse e-mail valide",
+	ValidationMinLength: "Doit contenir au moins {min} caractères",
+	ValidationMaxLength: "Doit contenir au plus {max} caractères",
+	ValidationMin:       "Doit être au moins {min}",
+	ValidationMax:       "Doit être au plus {max}",
+	ValidationOneOf:     "Doit être l'une des valeurs : {options}",
+	ValidationUUID:      "Doit être un UUID valide",
+	ValidationRegex:     "Le format est invalide",
+	ValidationEqField:   "Doit correspondre à {field}",
+	ValidationInvalid:   "Est invalide",
+	ValidationReadOnly:  "Ne peut pas être modifié",
+	ValidationURL:       "Doit être une URL http ou https",
+	ValidationTimezone:  "Doit être un fuseau horaire IANA comme Europe/Paris",
+
+	PasswordTooShort:      "Doit contenir au moins {min} caractères",
+	PasswordTooLong:       "Doit contenir au plus {max} octets",
+	PasswordMissingUpper:  "Doit contenir une lettre majuscule",
+	PasswordMissingLower:  "Doit contenir une lettre minuscule",
+	PasswordMissingDigit:  "Doit contenir un chiffre",
+	PasswordMissingSymbol: "Doit contenir un symbole",
+	PasswordPersonalInfo:  "Ne doit pas contenir votre nom ni votre adresse e-mail",
+	PasswordBreached:      "Ce mot de passe est apparu dans une fuite de données ; choisissez-en un autre",
+	PasswordReused:        "Ne doit correspondre à aucun de vos mots de passe récents",
+}
@@ -0,0 +1,104 @@
+package i18n
+
+// Error codes returned in API error responses.
+const (
+	ErrInternal                = "internal_error"
+	ErrBadRequest              = "bad_request"
+	ErrNotFound                = "not_found"
+	ErrMethodNotAllowed        = "method_not_allowed"
+	ErrRequestTooLarge         = "request_too_large"
+	ErrInvalidPayload          = "invalid_payload"
+	ErrValidationFailed        = "validation_failed"
+	ErrUnauthorized            = "unauthorized"
+	ErrForbidden               = "forbidden"
+	ErrTooManyRequests         = "too_many_requests"
+	ErrDatabase                = "database_error"
+	ErrUserNotFound            = "user_not_found"
+	ErrInvalidUserID           = "invalid_user_id"
+	ErrInvalidFilter           = "invalid_filter"
+	ErrUserCreateFailed        = "user_create_failed"
+	ErrTokenGenerationFailed   = "token_generation_failed"
+	ErrPasswordHashFailed      = "password_hash_failed"
+	ErrPasswordUpdateFailed    = "password_update_failed"
+	ErrPasswordResetFailed     = "password_reset_failed"
+	ErrRoleUpdateFailed        = "role_update_failed"
+	ErrEmailSendFailed         = "email_send_failed"
+	ErrEmailChangeFailed       = "email_change_failed"
+	ErrAccountDeleteFailed     = "account_delete_failed"
+	ErrInvitationCreateFailed  = "invitation_create_failed"
+	ErrInvitationRedeemFailed  = "invitation_redeem_failed"
+	ErrImpersonationLogFailed  = "impersonation_record_failed"
+	ErrInvalidCredentials      = "invalid_credentials"
+	ErrIncorrectPassword       = "incorrect_password"
+	ErrIncorrectCurrentPass    = "incorrect_current_password"
+	ErrInvalidRefreshToken     = "invalid_refresh_token"
+	ErrInvalidResetToken       = "invalid_reset_token"
+	ErrVerificationTokenNeeded = "verification_token_required"
+	ErrInvalidVerification     = "invalid_verification_token"
+	ErrConfirmationTokenNeeded = "confirmation_token_required"
+	ErrInvalidConfirmation     = "invalid_confirmation_token"
+	ErrMissingAuthHeader       = "missing_authorization_header"
+	ErrInvalidAuthHeader       = "invalid_authorization_header"
+	ErrInvalidToken            = "invalid_token"
+	ErrEmailExists             = "email_already_exists"
+	ErrUsernameTaken           = "username_taken"
+	ErrEmailUnchanged          = "email_unchanged"
+	ErrPasswordPolicy          = "password_policy_violation"
+	ErrRegistrationClosed      = "registration_closed"
+	ErrInvitationRequired      = "invitation_required"
+	ErrInvitationInvalid       = "invitation_invalid"
+	ErrEmailDomainNotAllowed   = "email_domain_not_allowed"
+	ErrDisposableEmail         = "disposable_email_not_allowed"
+	ErrImpersonationRestricted = "impersonation_restricted"
+	ErrImpersonationNested     = "impersonation_nested"
+	ErrImpersonationSelf       = "impersonation_self"
+	ErrImpersonationSuperadmin = "impersonation_superadmin"
+	ErrRoleChangeSelf          = "role_change_self"
+	ErrInvalidRole             = "invalid_role"
+	ErrLastSuperadmin          = "last_superadmin"
+	ErrInvalidSetupToken       = "invalid_setup_token"
+	ErrSetupUnavailable        = "setup_unavailable"
+	ErrPasswordChangeRequired  = "password_change_required"
+	ErrOAuthCodeMissing        = "oauth_code_missing"
+	ErrOAuthFailed             = "oauth_failed"
+	ErrAccountDeleted          = "account_deleted"
+	ErrIdentityLinkFailed      = "identity_link_failed"
+	ErrVersionConflict         = "version_conflict"
+	ErrPreconditionFailed      = "precondition_failed"
+	ErrUnsupportedMediaType    = "unsupported_media_type"
+	ErrInvalidPatch            = "invalid_patch"
+	ErrPatchTestFailed         = "patch_test_failed"
+	ErrFileRequired            = "file_required"
+	ErrFileTooLarge            = "file_too_large"
+	ErrUnsupportedFileType     = "unsupported_file_type"
+	ErrInvalidImage            = "invalid_image"
+	ErrUploadFailed            = "upload_failed"
+)
+
+// Validation codes reported per field.
+const (
+	ValidationRequired  = "validation.required"
+	ValidationEmail     = "validation.email"
+	ValidationMinLength = "validation.min_length"
+	ValidationMaxLength = "validation.max_length"
+	ValidationMin       = "validation.min"
+	ValidationMax       = "validation.max"
+	ValidationOneOf     = "validation.oneof"
+	ValidationUUID      = "validation.uuid"
+	ValidationRegex     = "validation.regex"
+	ValidationEqField   = "validation.eqfield"
+	ValidationInvalid   = "validation.invalid"
+	ValidationReadOnly  = "validation.read_only"
+	ValidationURL       = "validation.url"
+	ValidationTimezone  = "validation.timezone"
+
+	PasswordTooShort      = "password.too_short"
+	PasswordTooLong       = "password.too_long"
+	PasswordMissingUpper  = "password.missing_upper"
+	PasswordMissingLower  = "password.missing_lower"
+	PasswordMissingDigit  = "password.missing_digit"
+	PasswordMissingSymbol = "password.missing_symbol"
+	PasswordPersonalInfo  = "password.personal_info"
+	PasswordBreached      = "password.breached"
+	PasswordReused        = "password.reused"
+)
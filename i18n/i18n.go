@@ -0,0 +1,96 @@
+// Package i18n holds the message catalogs used for API errors, validation
+// messages and emails, keyed by stable message codes.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when no supported locale matches the request.
+const DefaultLocale = "en"
+
+// Catalog maps message codes to translated messages. Messages may contain
+// {name} placeholders filled from the params passed to T.
+type Catalog map[string]string
+
+var catalogs = map[string]Catalog{
+	"en": english,
+	"es": spanish,
+	"fr": french,
+}
+
+// Supported returns the available locales.
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Lookup returns the message for code in locale without falling back.
+func Lookup(locale, code string) (string, bool) {
+	msg, ok := catalogs[locale][code]
+	return msg, ok
+}
+
+// T translates code into locale, falling back to the default locale and
+// finally to the code itself.
+func T(locale, code string, params map[string]interface{}) string {
+	msg, ok := Lookup(locale, code)
+	if !ok {
+		if msg, ok = Lookup(DefaultLocale, code); !ok {
+			return code
+		}
+	}
+	for key, value := range params {
+		msg = strings.ReplaceAll(msg, "{"+key+"}", fmt.Sprint(value))
+	}
+	return msg
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header,
+// honouring quality values and falling back from regional tags ("es-MX") to
+// their base language.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if _, ok := catalogs[c.tag]; ok {
+			return c.tag
+		}
+		base, _, _ := strings.Cut(c.tag, "-")
+		if _, ok := catalogs[base]; ok {
+			return base
+		}
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email messages.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// LogMailer writes messages to the application log instead of sending them.
+// It is used when no SMTP server is configured.
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	log.Printf("mail to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// SMTPMailer sends messages through an SMTP server using PLAIN auth.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{msg.To}, []byte(b.String()))
+}
+
+// New returns the SMTP mailer when SMTP_HOST is configured and the log mailer otherwise.
+func New(cfg config.Config) Mailer {
+	if cfg.SMTPHost == "" {
+		return LogMailer{}
+	}
+	return SMTPMailer{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.MailFrom,
+	}
+}
+
+// SendTemplate renders the named template in locale and delivers it to the
+// recipient with m.
+func SendTemplate(m Mailer, to, locale, name string, data interface{}) error {
+	subject, body, err := Render(locale, name, data)
+	if err != nil {
+		return err
+	}
+	return m.Send(Message{To: to, Subject: subject, Body: body})
+}
@@ -0,0 +1,195 @@
+package mailer
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ElvinEga/gofiber_starter/i18n"
+)
+
+// Template names.
+const (
+	TemplatePasswordReset      = "password_reset"
+	TemplateEmailChangeConfirm = "email_change_confirm"
+	TemplateEmailChangeNotice  = "email_change_notice"
+	TemplatePasswordChanged    = "password_changed"
+	TemplateAccountDelete      = "account_delete"
+	// TemplateEmailChangeAuth authorizes the email change of an account
+	// without a password.
+	TemplateEmailChangeAuth = "email_change_auth"
+)
+
+type emailTemplate struct {
+	subject string
+	body    *template.Template
+}
+
+func newTemplate(name, subject, body string) emailTemplate {
+	return emailTemplate{subject: subject, body: template.Must(template.New(name).Parse(body))}
+}
+
+// templates holds every email per locale; locales missing a template fall
+// back to i18n.DefaultLocale.
+var templates = map[string]map[string]emailTemplate{
+	"en": {
+		TemplatePasswordReset: newTemplate(TemplatePasswordReset, "Reset your password", `Hello {{.Name}},
+
+We received a request to reset your password. Use the link below within the next hour:
+
+{{.Link}}
+
+If you did not request this, you can ignore this email.
+`),
+		TemplateEmailChangeConfirm: newTemplate(TemplateEmailChangeConfirm, "Confirm your new email address", `Hello {{.Name}},
+
+Please confirm that you want to use this address for your account:
+
+{{.Link}}
+
+The link expires in 24 hours. If you did not request this change, ignore this email.
+`),
+		TemplateEmailChangeNotice: newTemplate(TemplateEmailChangeNotice, "Your email address is being changed", `Hello {{.Name}},
+
+A request was made to change the email address of your account to {{.NewEmail}}.
+The change takes effect once the new address is confirmed.
+
+If this was not you, change your password immediately and contact support.
+`),
+		TemplatePasswordChanged: newTemplate(TemplatePasswordChanged, "Your password was changed", `Hello {{.Name}},
+
+The password of your account was changed on {{.Time}} from IP address {{.IP}}.
+All other sessions have been signed out.
+
+If you did not make this change, reset your password immediately and contact support.
+`),
+		TemplateEmailChangeAuth: newTemplate(TemplateEmailChangeAuth, "Confirm the change of your email address", `Hello {{.Name}},
+
+We received a request to change the email address of your account to {{.NewEmail}}. To confirm, use the link below within the next hour while signed in:
+
+{{.Link}}
+
+If you did not request this, ignore this email and sign out of your other sessions.
+`),
+		TemplateAccountDelete: newTemplate(TemplateAccountDelete, "Confirm the deletion of your account", `Hello {{.Name}},
+
+We received a request to delete your account. To confirm, use the link below within the next hour while signed in:
+
+{{.Link}}
+
+If you did not request this, ignore this email and sign out of your other sessions.
+`),
+	},
+	"es": {
+		TemplatePasswordReset: newTemplate(TemplatePasswordReset, "Restablece tu contraseña", `Hola {{.Name}}:
+
+Hemos recibido una solicitud para restablecer tu contraseña. Usa el siguiente enlace durante la próxima hora:
+
+{{.Link}}
+
+Si no lo has solicitado, puedes ignorar este correo.
+`),
+		TemplateEmailChangeConfirm: newTemplate(TemplateEmailChangeConfirm, "Confirma tu nueva dirección de correo", `Hola {{.Name}}:
+
+Confirma que quieres usar esta dirección para tu cuenta:
+
+{{.Link}}
+
+El enlace caduca en 24 horas. Si no has solicitado este cambio, ignora este correo.
+`),
+		TemplateEmailChangeNotice: newTemplate(TemplateEmailChangeNotice, "Se está cambiando tu dirección de correo", `Hola {{.Name}}:
+
+Se ha solicitado cambiar la dirección de correo de tu cuenta a {{.NewEmail}}.
+El cambio se aplicará cuando se confirme la nueva dirección.
+
+Si no has sido tú, cambia tu contraseña de inmediato y contacta con soporte.
+`),
+		TemplatePasswordChanged: newTemplate(TemplatePasswordChanged, "Tu contraseña ha cambiado", `Hola {{.Name}}:
+
+La contraseña de tu cuenta se cambió el {{.Time}} desde la dirección IP {{.IP}}.
+Se han cerrado todas las demás sesiones.
+
+Si no has hecho este cambio, restablece tu contraseña de inmediato y contacta con soporte.
+`),
+		TemplateEmailChangeAuth: newTemplate(TemplateEmailChangeAuth, "Confirma el cambio de tu dirección de correo", `Hola {{.Name}}:
+
+Hemos recibido una solicitud para cambiar la dirección de correo de tu cuenta a {{.NewEmail}}. Para confirmarla, usa el siguiente enlace durante la próxima hora con la sesión iniciada:
+
+{{.Link}}
+
+Si no lo has solicitado, ignora este correo y cierra tus demás sesiones.
+`),
+		TemplateAccountDelete: newTemplate(TemplateAccountDelete, "Confirma la eliminación de tu cuenta", `Hola {{.Name}}:
+
+Hemos recibido una solicitud para eliminar tu cuenta. Para confirmarla, usa el siguiente enlace durante la próxima hora con la sesión iniciada:
+
+{{.Link}}
+
+Si no lo has solicitado, ignora este correo y cierra tus demás sesiones.
+`),
+	},
+	"fr": {
+		TemplatePasswordReset: newTemplate(TemplatePasswordReset, "Réinitialisez votre mot de passe", `Bonjour {{.Name}},
+
+Nous avons reçu une demande de réinitialisation de votre mot de passe. Utilisez le lien ci-dessous dans l'heure :
+
+{{.Link}}
+
+Si vous n'êtes pas à l'origine de cette demande, ignorez cet e-mail.
+`),
+		TemplateEmailChangeConfirm: newTemplate(TemplateEmailChangeConfirm, "Confirmez votre nouvelle adresse e-mail", `Bonjour {{.Name}},
+
+Veuillez confirmer que vous souhaitez utiliser cette adresse pour votre compte :
+
+{{.Link}}
+
+Le lien expire dans 24 heures. Si vous n'avez pas demandé ce changement, ignorez cet e-mail.
+`),
+		TemplateEmailChangeNotice: newTemplate(TemplateEmailChangeNotice, "Votre adresse e-mail est en cours de modification", `Bonjour {{.Name}},
+
+Une demande a été faite pour remplacer l'adresse e-mail de votre compte par {{.NewEmail}}.
+Le changement prendra effet une fois la nouvelle adresse confirmée.
+
+Si ce n'était pas vous, changez immédiatement votre mot de passe et contactez le support.
+`),
+		TemplatePasswordChanged: newTemplate(TemplatePasswordChanged, "Votre mot de passe a été modifié", `Bonjour {{.Name}},
+
+Le mot de passe de votre compte a été modifié le {{.Time}} depuis l'adresse IP {{.IP}}.
+Toutes les autres sessions ont été déconnectées.
+
+Si vous n'êtes pas à l'origine de ce changement, réinitialisez immédiatement votre mot de passe et contactez le support.
+`),
+		TemplateEmailChangeAuth: newTemplate(TemplateEmailChangeAuth, "Confirmez le changement de votre adresse e-mail", `Bonjour {{.Name}},
+
+Nous avons reçu une demande pour remplacer l'adresse e-mail de votre compte par {{.NewEmail}}. Pour la confirmer, utilisez le lien ci-dessous dans l'heure, en étant connecté :
+
+{{.Link}}
+
+Si vous n'êtes pas à l'origine de cette demande, ignorez cet e-mail et déconnectez vos autres sessions.
+`),
+		TemplateAccountDelete: newTemplate(TemplateAccountDelete, "Confirmez la suppression de votre compte", `Bonjour {{.Name}},
+
+Nous avons reçu une demande de suppression de votre compte. Pour la confirmer, utilisez le lien ci-dessous dans l'heure, en étant connecté :
+
+{{.Link}}
+
+Si vous n'êtes pas à l'origine de cette demande, ignorez cet e-mail et déconnectez vos autres sessions.
+`),
+	},
+}
+
+// Render executes the named template in locale and returns its subject and
+// body.
+func Render(locale, name string, data interface{}) (string, string, error) {
+	tmpl, ok := templates[locale][name]
+	if !ok {
+		if tmpl, ok = templates[i18n.DefaultLocale][name]; !ok {
+			return "", "", fmt.Errorf("unknown email template %q", name)
+		}
+	}
+	var body strings.Builder
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return tmpl.subject, body.String(), nil
+}
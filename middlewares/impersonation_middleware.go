@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ForbidImpersonation blocks sensitive actions (password or MFA changes)
+// when the request is made with an impersonation token.
+func ForbidImpersonation() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if impersonatorID, ok := c.Locals("impersonatorID").(string); ok && impersonatorID != "" {
+			return apperror.Forbidden(i18n.ErrImpersonationRestricted)
+		}
+		return c.Next()
+	}
+}
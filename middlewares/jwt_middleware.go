@@ -1,21 +1,54 @@
 package middlewares
 
 import (
+	"context"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
 	"github.com/ElvinEga/gofiber_starter/utils"
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
 )
 
-func JWTProtected() fiber.Handler {
+// TokenCutoffs looks up the stored time before which a user's access tokens
+// were revoked, so revocations made by another process, such as the CLI,
+// take effect here too.
+type TokenCutoffs interface {
+	TokensValidAfter(ctx context.Context, userID uuid.UUID) (time.Time, error)
+}
+
+func JWTProtected(tokens *utils.TokenService, cutoffs TokenCutoffs) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		userID, role, err := utils.VerifyJWT(c)
+		claims, err := tokens.VerifyJWTClaims(c)
+		if err != nil {
+			return apperror.Unauthorized(i18n.ErrUnauthorized)
+		}
+		userID, err := uuid.Parse(claims.UserID)
+		if err != nil {
+			return apperror.Unauthorized(i18n.ErrUnauthorized)
+		}
+		validAfter, err := cutoffs.TokensValidAfter(c.Context(), userID)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Unauthorized",
-			})
+			return err
+		}
+		// A token without an issue time predates every revocation.
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		if issuedAt.Before(validAfter) {
+			return apperror.Unauthorized(i18n.ErrUnauthorized)
+		}
+
+		c.Locals("userID", claims.UserID)
+		c.Locals("userRole", claims.Role)
+		if claims.Act != nil {
+			c.Locals("impersonatorID", claims.Act.Sub)
+		}
+		if claims.PasswordChange {
+			c.Locals("passwordChangeRequired", true)
 		}
-		c.Locals("userID", userID)
-		c.Locals("userRole", role)
 		return c.Next()
 	}
 }
@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Locale negotiates the response language from the Accept-Language header
+// and stores it in c.Locals("locale").
+func Locale() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		locale := i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+		c.Locals("locale", locale)
+		c.Set(fiber.HeaderContentLanguage, locale)
+		c.Vary(fiber.HeaderAcceptLanguage)
+		return c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/gofiber/fiber/v3"
+)
+
+// RequirePasswordChanged refuses requests made with a token issued to a user
+// who must change their password, except for the allowed routes, given as
+// "METHOD /path".
+func RequirePasswordChanged(allowed ...string) fiber.Handler {
+	allow := make(map[string]bool, len(allowed))
+	for _, route := range allowed {
+		allow[route] = true
+	}
+	return func(c fiber.Ctx) error {
+		if required, _ := c.Locals("passwordChangeRequired").(bool); required && !allow[c.Method()+" "+c.Path()] {
+			return apperror.Forbidden(i18n.ErrPasswordChangeRequired)
+		}
+		return c.Next()
+	}
+}
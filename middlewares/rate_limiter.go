@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/limiter"
 	"time"
@@ -14,10 +16,7 @@ func RateLimit() fiber.Handler {
 			return c.IP()
 		},
 		LimitReached: func(c fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Too many requests",
-			})
+			return apperror.TooManyRequests(i18n.ErrTooManyRequests)
 		},
 	})
 }
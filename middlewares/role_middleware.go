@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -9,7 +11,7 @@ func RequireRole(role string) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		userRole := c.Locals("userRole") // set in your JWT middleware
 		if userRole != role {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+			return apperror.Forbidden(i18n.ErrForbidden)
 		}
 		return c.Next()
 	}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit outcomes.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEvent is an append-only record of a security relevant action.
+type AuditEvent struct {
+	ID        uuid.UUID  `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Action    string     `gorm:"index" json:"action"`
+	Outcome   string     `gorm:"index" json:"outcome"`
+	ActorID   *uuid.UUID `gorm:"type:varchar(36);index" json:"actor_id"`
+	TargetID  *uuid.UUID `gorm:"type:varchar(36);index" json:"target_id"`
+	IPAddress string     `json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	Metadata  JSONMap    `gorm:"type:text" json:"metadata,omitempty"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime;index" json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationLog records every impersonation token issued by an administrator.
+type ImpersonationLog struct {
+	ID             uuid.UUID `gorm:"type:varchar(36);primaryKey" json:"id"`
+	ImpersonatorID uuid.UUID `gorm:"type:varchar(36);index" json:"impersonator_id"`
+	TargetUserID   uuid.UUID `gorm:"type:varchar(36);index" json:"target_user_id"`
+	Reason         string    `json:"reason"`
+	IPAddress      string    `json:"ip_address"`
+	UserAgent      string    `json:"user_agent"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
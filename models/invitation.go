@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation grants the right to register while REGISTRATION_MODE is invite_only.
+// An invitation bound to an email can only be redeemed by that address.
+type Invitation struct {
+	ID        uuid.UUID  `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Code      string     `gorm:"uniqueIndex" json:"code"`
+	Email     string     `gorm:"index" json:"email"`
+	CreatedBy uuid.UUID  `gorm:"type:varchar(36)" json:"created_by"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	UsedBy    *uuid.UUID `gorm:"type:varchar(36)" json:"used_by"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordHistory keeps previous password hashes so they cannot be reused.
+type PasswordHistory struct {
+	ID        uuid.UUID `gorm:"type:varchar(36);primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:varchar(36);index" json:"user_id"`
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;index" json:"created_at"`
+}
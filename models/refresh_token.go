@@ -8,8 +8,8 @@ import (
 )
 
 type RefreshToken struct {
-	ID        uuid.UUID      `gorm:"type:text;primaryKey" json:"id"`
-	UserID    uuid.UUID      `gorm:"type:text;index" json:"user_id"`
+	ID        uuid.UUID      `gorm:"type:varchar(36);primaryKey" json:"id"`
+	UserID    uuid.UUID      `gorm:"type:varchar(36);index" json:"user_id"`
 	Token     string         `gorm:"uniqueIndex" json:"token"`
 	ExpiresAt time.Time      `json:"expires_at"`
 	CreatedAt time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
@@ -7,19 +7,60 @@ import (
 	"gorm.io/gorm"
 )
 
+// Roles known to the application.
+const (
+	RoleUser       = "user"
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "superadmin"
+)
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleAdmin, RoleSuperAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
-	ID                uuid.UUID      `gorm:"type:text;primaryKey" json:"id"`
-	Name              string         `json:"name"`
-	Username          string         `gorm:"uniqueIndex" json:"username"`
-	Email             string         `gorm:"uniqueIndex" json:"email"`
-	Password          string         `json:"-"`
-	Role              string         `json:"role"`
-	IsVerified        bool           `json:"is_verified"`
-	EmailVerifiedAt   time.Time      `json:"email_verified_at"`
-	VerificationToken string         `json:"-"`
-	ResetToken        string         `json:"-"`
-	ResetExpiresAt    time.Time      `json:"reset_expires_at"`
-	CreatedAt         time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
-	UpdatedAt         time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
-	DeletedAt         gorm.DeletedAt `gorm:"column:deleted_at" json:"deleted_at"`
+	ID                 uuid.UUID `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Name               string    `json:"name"`
+	Username           string    `gorm:"uniqueIndex" json:"username"`
+	Email              string    `gorm:"uniqueIndex" json:"email"`
+	AvatarURL          string    `json:"avatar_url"`
+	AvatarThumbnailURL string    `json:"avatar_thumbnail_url"`
+	// AvatarKey is the storage key of an uploaded avatar, empty when the
+	// avatar is an external URL.
+	AvatarKey            string    `json:"-"`
+	Bio                  string    `json:"bio"`
+	Locale               string    `json:"locale"`
+	Timezone             string    `json:"timezone"`
+	Phone                string    `json:"phone"`
+	Metadata             JSONMap   `gorm:"type:text" json:"metadata"`
+	Password             string    `json:"-"`
+	Role                 string    `json:"role"`
+	IsVerified           bool      `json:"is_verified"`
+	MustChangePassword   bool      `json:"must_change_password"`
+	EmailVerifiedAt      time.Time `json:"email_verified_at"`
+	VerificationToken    string    `json:"-"`
+	ResetToken           string    `json:"-"`
+	ResetExpiresAt       time.Time `json:"reset_expires_at"`
+	PendingEmail         string    `json:"pending_email"`
+	EmailChangeToken     string    `gorm:"index" json:"-"`
+	EmailChangeExpiresAt time.Time `json:"-"`
+	// EmailChangeAuthToken authorizes the email change of an account
+	// without a password; it is emailed to the current address.
+	EmailChangeAuthToken     string    `json:"-"`
+	EmailChangeAuthExpiresAt time.Time `json:"-"`
+	DeletionToken            string    `json:"-"`
+	DeletionExpiresAt        time.Time `json:"-"`
+	// TokensValidAfter rejects access tokens issued before it, in every
+	// server process. Stored with microsecond precision, like token issue
+	// times.
+	TokensValidAfter time.Time      `json:"-"`
+	Version          int64          `gorm:"not null" json:"version"`
+	CreatedAt        time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"column:deleted_at" json:"deleted_at"`
 }
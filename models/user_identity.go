@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity providers.
+const (
+	ProviderGoogle = "google"
+)
+
+// UserIdentity links a user to an account at an external identity provider.
+type UserIdentity struct {
+	ID        uuid.UUID `gorm:"type:varchar(36);primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:varchar(36);index" json:"user_id"`
+	Provider  string    `gorm:"uniqueIndex:idx_identity_provider_subject" json:"provider"`
+	Subject   string    `gorm:"uniqueIndex:idx_identity_provider_subject" json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
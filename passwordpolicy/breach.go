@@ -0,0 +1,49 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachChecker reports whether a password is part of a known breach corpus.
+type BreachChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// RangeDirChecker looks passwords up in an offline copy of a k-anonymity
+// range dataset, laid out like the Have I Been Pwned range API: one file per
+// five character SHA-1 prefix (e.g. "5BAA6.txt") holding "SUFFIX:COUNT" lines.
+// Only the matching range file is read, so the full corpus never has to be
+// loaded into memory.
+type RangeDirChecker struct {
+	Dir string
+}
+
+func (r RangeDirChecker) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	file, err := os.Open(filepath.Join(r.Dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		hash, count, _ := strings.Cut(line, ":")
+		if strings.EqualFold(hash, suffix) && count != "0" {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,129 @@
+package passwordpolicy
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/utils"
+)
+
+// Policy describes the rules a new password must satisfy.
+type Policy struct {
+	// MinLength is counted in characters.
+	MinLength int
+	// MaxBytes bounds the UTF-8 encoded length, because bcrypt ignores
+	// everything past 72 bytes.
+	MaxBytes      int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DisallowPersonalInfo rejects passwords containing the user's email
+	// local part or any part of their name.
+	DisallowPersonalInfo bool
+	// HistorySize is the number of previous passwords that may not be reused.
+	HistorySize int
+	// Breached, when set, rejects passwords found in a breach corpus.
+	Breached BreachChecker
+}
+
+// FromConfig builds the policy from the application configuration.
+func FromConfig(cfg config.Config) Policy {
+	policy := Policy{
+		MinLength:            cfg.PasswordMinLength,
+		MaxBytes:             cfg.PasswordMaxBytes,
+		RequireUpper:         cfg.PasswordRequireUpper,
+		RequireLower:         cfg.PasswordRequireLower,
+		RequireDigit:         cfg.PasswordRequireDigit,
+		RequireSymbol:        cfg.PasswordRequireSymbol,
+		DisallowPersonalInfo: true,
+		HistorySize:          cfg.PasswordHistorySize,
+	}
+	if cfg.BreachedPasswordsDir != "" {
+		policy.Breached = RangeDirChecker{Dir: cfg.BreachedPasswordsDir}
+	}
+	return policy
+}
+
+// Validate checks password against the policy. field names the request field
+// reported in the errors and personal lists values (email, name) the password
+// must not contain.
+func (p Policy) Validate(field, password string, personal ...string) []utils.ValidationError {
+	var errs []utils.ValidationError
+	fail := func(code string, params map[string]interface{}) {
+		errs = append(errs, utils.NewValidationError(field, code, params))
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		fail(i18n.PasswordTooShort, map[string]interface{}{"min": p.MinLength})
+	}
+	if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		fail(i18n.PasswordTooLong, map[string]interface{}{"max": p.MaxBytes})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		fail(i18n.PasswordMissingUpper, nil)
+	}
+	if p.RequireLower && !hasLower {
+		fail(i18n.PasswordMissingLower, nil)
+	}
+	if p.RequireDigit && !hasDigit {
+		fail(i18n.PasswordMissingDigit, nil)
+	}
+	if p.RequireSymbol && !hasSymbol {
+		fail(i18n.PasswordMissingSymbol, nil)
+	}
+
+	if p.DisallowPersonalInfo && containsPersonalInfo(password, personal) {
+		fail(i18n.PasswordPersonalInfo, nil)
+	}
+
+	if p.Breached != nil && len(errs) == 0 {
+		breached, err := p.Breached.IsBreached(password)
+		if err != nil {
+			// An unreadable dataset must not lock users out; skip the check.
+			return errs
+		}
+		if breached {
+			fail(i18n.PasswordBreached, nil)
+		}
+	}
+
+	return errs
+}
+
+// containsPersonalInfo reports whether password contains the email local part
+// or any name token of at least three characters.
+func containsPersonalInfo(password string, personal []string) bool {
+	lower := strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(value)
+		if at := strings.Index(value, "@"); at >= 0 {
+			value = value[:at]
+		}
+		for _, token := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if utf8.RuneCountInString(token) >= 3 && strings.Contains(lower, token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditEventFilter narrows an audit log query; zero values match everything.
+type AuditEventFilter struct {
+	Action   string
+	Outcome  string
+	ActorID  *uuid.UUID
+	TargetID *uuid.UUID
+	From     *time.Time
+	To       *time.Time
+}
+
+// AuditEventRepository persists the security audit log.
+type AuditEventRepository interface {
+	Create(ctx context.Context, event *models.AuditEvent) error
+	// List returns a page of matching events, newest first, and the total
+	// number of matches.
+	List(ctx context.Context, filter AuditEventFilter, limit, offset int) ([]models.AuditEvent, int64, error)
+	// ListForUser returns events the user acted in or was the target of.
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]models.AuditEvent, error)
+	// AnonymizeForUser strips identifying details from the user's events.
+	AnonymizeForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type gormAuditEventRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormAuditEventRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *gormAuditEventRepository) List(ctx context.Context, filter AuditEventFilter, limit, offset int) ([]models.AuditEvent, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.AuditEvent{})
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []models.AuditEvent
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+func (r *gormAuditEventRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	err := r.db.WithContext(ctx).
+		Where("actor_id = ? OR target_id = ?", userID, userID).
+		Order("created_at").Find(&events).Error
+	return events, err
+}
+
+func (r *gormAuditEventRepository) AnonymizeForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.AuditEvent{}).
+		Where("actor_id = ? OR target_id = ?", userID, userID).
+		Updates(map[string]interface{}{"ip_address": "", "user_agent": "", "metadata": nil}).Error
+}
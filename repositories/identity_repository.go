@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IdentityRepository persists external identities linked to users.
+type IdentityRepository interface {
+	// Link stores identity unless its provider and subject are already known.
+	Link(ctx context.Context, identity *models.UserIdentity) error
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]models.UserIdentity, error)
+	DeleteForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type gormIdentityRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormIdentityRepository) Link(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).
+		Where(models.UserIdentity{Provider: identity.Provider, Subject: identity.Subject}).
+		FirstOrCreate(identity).Error
+}
+
+func (r *gormIdentityRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+func (r *gormIdentityRepository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserIdentity{}).Error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"gorm.io/gorm"
+)
+
+// ImpersonationLogRepository persists issued impersonation tokens.
+type ImpersonationLogRepository interface {
+	Create(ctx context.Context, entry *models.ImpersonationLog) error
+}
+
+type gormImpersonationLogRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormImpersonationLogRepository) Create(ctx context.Context, entry *models.ImpersonationLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvitationUsed is returned by MarkUsed when the invitation was redeemed
+// by someone else since it was read.
+var ErrInvitationUsed = errors.New("invitation already used")
+
+// InvitationRepository persists registration invitations.
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *models.Invitation) error
+	// FindUsableByCode returns the unused, unexpired invitation with code.
+	FindUsableByCode(ctx context.Context, code string, now time.Time) (*models.Invitation, error)
+	// FindUsableByEmail returns an unused, unexpired invitation bound to email.
+	FindUsableByEmail(ctx context.Context, email string, now time.Time) (*models.Invitation, error)
+	// MarkUsed redeems the invitation for userID, failing with
+	// ErrInvitationUsed when it has already been redeemed.
+	MarkUsed(ctx context.Context, invitation *models.Invitation, userID uuid.UUID, at time.Time) error
+}
+
+type gormInvitationRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormInvitationRepository) Create(ctx context.Context, invitation *models.Invitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+func (r *gormInvitationRepository) findUsable(ctx context.Context, now time.Time, query string, arg interface{}) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.WithContext(ctx).
+		Where("used_at IS NULL AND expires_at > ?", now).
+		Where(query, arg).
+		First(&invitation).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &invitation, nil
+}
+
+func (r *gormInvitationRepository) FindUsableByCode(ctx context.Context, code string, now time.Time) (*models.Invitation, error) {
+	return r.findUsable(ctx, now, "code = ?", code)
+}
+
+func (r *gormInvitationRepository) FindUsableByEmail(ctx context.Context, email string, now time.Time) (*models.Invitation, error) {
+	return r.findUsable(ctx, now, "LOWER(email) = ?", strings.ToLower(email))
+}
+
+func (r *gormInvitationRepository) MarkUsed(ctx context.Context, invitation *models.Invitation, userID uuid.UUID, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(invitation).
+		Where("used_at IS NULL").
+		Updates(map[string]interface{}{
+			"used_at": at,
+			"used_by": userID,
+		})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrInvitationUsed
+	}
+	return result.Error
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordHistoryRepository persists the hashes of users' previous passwords.
+type PasswordHistoryRepository interface {
+	// Recent returns up to limit entries, newest first.
+	Recent(ctx context.Context, userID uuid.UUID, limit int) ([]models.PasswordHistory, error)
+	// Add stores entry and keeps only the newest keep entries of the user.
+	Add(ctx context.Context, entry *models.PasswordHistory, keep int) error
+	// DeleteForUser removes every entry of the user.
+	DeleteForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type gormPasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormPasswordHistoryRepository) Recent(ctx context.Context, userID uuid.UUID, limit int) ([]models.PasswordHistory, error) {
+	var history []models.PasswordHistory
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("created_at DESC").Limit(limit).
+		Find(&history).Error
+	return history, err
+}
+
+func (r *gormPasswordHistoryRepository) Add(ctx context.Context, entry *models.PasswordHistory, keep int) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Create(entry).Error; err != nil {
+		return err
+	}
+
+	// OFFSET without LIMIT is not valid everywhere, so skip the kept entries here.
+	var ids []uuid.UUID
+	if err := db.Model(&models.PasswordHistory{}).
+		Where("user_id = ?", entry.UserID).
+		Order("created_at DESC").
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) <= keep {
+		return nil
+	}
+	return db.Where("id IN ?", ids[keep:]).Delete(&models.PasswordHistory{}).Error
+}
+
+func (r *gormPasswordHistoryRepository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.PasswordHistory{}).Error
+}
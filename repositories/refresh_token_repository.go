@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository persists refresh tokens, one per session.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	// FindValid returns the unexpired token with the given value.
+	FindValid(ctx context.Context, token string, now time.Time) (*models.RefreshToken, error)
+	// FindValidForUser is FindValid restricted to the user's own tokens.
+	FindValidForUser(ctx context.Context, token string, userID uuid.UUID, now time.Time) (*models.RefreshToken, error)
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]models.RefreshToken, error)
+	// Delete revokes token. It returns ErrNotFound when the token was revoked
+	// already, so of two concurrent rotations only one succeeds.
+	Delete(ctx context.Context, token *models.RefreshToken) error
+	// DeleteForUser removes the user's tokens except the one valued except,
+	// when given.
+	DeleteForUser(ctx context.Context, userID uuid.UUID, except string) error
+	// PurgeForUser permanently removes every token of the user.
+	PurgeForUser(ctx context.Context, userID uuid.UUID) error
+	// PurgeExpired permanently removes tokens expired by now or already
+	// revoked, returning how many were removed.
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type gormRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *gormRefreshTokenRepository) FindValid(ctx context.Context, token string, now time.Time) (*models.RefreshToken, error) {
+	var refreshToken models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token = ? AND expires_at > ?", token, now).First(&refreshToken).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &refreshToken, nil
+}
+
+func (r *gormRefreshTokenRepository) FindValidForUser(ctx context.Context, token string, userID uuid.UUID, now time.Time) (*models.RefreshToken, error) {
+	var refreshToken models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token = ? AND user_id = ? AND expires_at > ?", token, userID, now).First(&refreshToken).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &refreshToken, nil
+}
+
+func (r *gormRefreshTokenRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *gormRefreshTokenRepository) Delete(ctx context.Context, token *models.RefreshToken) error {
+	result := r.db.WithContext(ctx).Delete(token)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormRefreshTokenRepository) DeleteForUser(ctx context.Context, userID uuid.UUID, except string) error {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if except != "" {
+		query = query.Where("token <> ?", except)
+	}
+	return query.Delete(&models.RefreshToken{}).Error
+}
+
+func (r *gormRefreshTokenRepository) PurgeForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error
+}
+
+func (r *gormRefreshTokenRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("expires_at <= ? OR deleted_at IS NOT NULL", now).
+		Delete(&models.RefreshToken{})
+	return result.RowsAffected, result.Error
+}
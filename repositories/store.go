@@ -0,0 +1,97 @@
+// Package repositories defines the persistence interfaces used by services,
+// with GORM implementations over a *gorm.DB.
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ElvinEga/gofiber_starter/database"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when a lookup matches no record.
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicate is returned when a write would break a unique index.
+var ErrDuplicate = errors.New("duplicate record")
+
+// ErrStale is returned when a versioned record was changed by someone else
+// since it was read.
+var ErrStale = errors.New("record was changed concurrently")
+
+// Store groups the repositories and runs units of work atomically.
+type Store interface {
+	Users() UserRepository
+	RefreshTokens() RefreshTokenRepository
+	Invitations() InvitationRepository
+	Identities() IdentityRepository
+	PasswordHistory() PasswordHistoryRepository
+	AuditEvents() AuditEventRepository
+	ImpersonationLogs() ImpersonationLogRepository
+
+	// Transaction runs fn with a Store whose repositories share one
+	// transaction, committed when fn returns nil. Called on a Store that is
+	// already transactional, fn joins the outer transaction.
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+}
+
+// GormStore implements Store on a *gorm.DB.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a Store backed by db.
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Users() UserRepository {
+	return &gormUserRepository{db: s.db}
+}
+
+func (s *GormStore) RefreshTokens() RefreshTokenRepository {
+	return &gormRefreshTokenRepository{db: s.db}
+}
+
+func (s *GormStore) Invitations() InvitationRepository {
+	return &gormInvitationRepository{db: s.db}
+}
+
+func (s *GormStore) Identities() IdentityRepository {
+	return &gormIdentityRepository{db: s.db}
+}
+
+func (s *GormStore) PasswordHistory() PasswordHistoryRepository {
+	return &gormPasswordHistoryRepository{db: s.db}
+}
+
+func (s *GormStore) AuditEvents() AuditEventRepository {
+	return &gormAuditEventRepository{db: s.db}
+}
+
+func (s *GormStore) ImpersonationLogs() ImpersonationLogRepository {
+	return &gormImpersonationLogRepository{db: s.db}
+}
+
+func (s *GormStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return database.WithTx(ctx, s.db, func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx})
+	})
+}
+
+// duplicate translates a unique index violation into ErrDuplicate.
+func duplicate(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// notFound translates GORM's missing-record error into ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserRepository persists user accounts. Lookups skip soft-deleted accounts
+// unless stated otherwise.
+type UserRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindByEmailIncludingDeleted also matches accounts pending deletion.
+	FindByEmailIncludingDeleted(ctx context.Context, email string) (*models.User, error)
+	FindByVerificationToken(ctx context.Context, token string) (*models.User, error)
+	FindByResetToken(ctx context.Context, token string, now time.Time) (*models.User, error)
+	FindByEmailChangeToken(ctx context.Context, token string, now time.Time) (*models.User, error)
+	// UsernameTaken reports whether another account uses username.
+	UsernameTaken(ctx context.Context, username string, exceptID uuid.UUID) (bool, error)
+	// EmailTaken reports whether another account, including one pending
+	// deletion, uses email (case-insensitively).
+	EmailTaken(ctx context.Context, email string, exceptID uuid.UUID) (bool, error)
+	// Create stores a new account at version 1. It returns ErrDuplicate when
+	// the email or username is taken.
+	Create(ctx context.Context, user *models.User) error
+	// Save writes every field of user and bumps its version. It returns
+	// ErrStale when the stored version is no longer user.Version, and
+	// ErrDuplicate when the email or username is taken.
+	Save(ctx context.Context, user *models.User) error
+	// UpdatePassword and UpdateRole change one field and bump the version.
+	UpdatePassword(ctx context.Context, user *models.User, hash string) error
+	UpdateRole(ctx context.Context, user *models.User, role string) error
+	// RevokeTokens stores at as the user's TokensValidAfter without bumping
+	// the version.
+	RevokeTokens(ctx context.Context, user *models.User, at time.Time) error
+	// TokensValidAfter returns the user's TokensValidAfter.
+	TokensValidAfter(ctx context.Context, id uuid.UUID) (time.Time, error)
+	// ListByRole returns the accounts holding role.
+	ListByRole(ctx context.Context, role string) ([]models.User, error)
+	// LockByRole is ListByRole that also locks the rows, in id order, until
+	// the transaction ends: a concurrent caller waits, then sees the changes
+	// made meanwhile. SQLite has no row locks; its single writer fails one of
+	// two such transactions instead.
+	LockByRole(ctx context.Context, role string) ([]models.User, error)
+	// Delete soft-deletes the account.
+	Delete(ctx context.Context, user *models.User) error
+	// FindDeletedBefore lists accounts soft-deleted before cutoff.
+	FindDeletedBefore(ctx context.Context, cutoff time.Time) ([]models.User, error)
+	// Purge permanently removes the account row.
+	Purge(ctx context.Context, user *models.User) error
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) first(ctx context.Context, query *gorm.DB, args ...interface{}) (*models.User, error) {
+	var user models.User
+	if err := query.WithContext(ctx).First(&user, args...).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return r.first(ctx, r.db, "id = ?", id)
+}
+
+func (r *gormUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.first(ctx, r.db, "email = ?", email)
+}
+
+func (r *gormUserRepository) FindByEmailIncludingDeleted(ctx context.Context, email string) (*models.User, error) {
+	return r.first(ctx, r.db.Unscoped(), "email = ?", email)
+}
+
+func (r *gormUserRepository) FindByVerificationToken(ctx context.Context, token string) (*models.User, error) {
+	return r.first(ctx, r.db, "verification_token = ?", token)
+}
+
+func (r *gormUserRepository) FindByResetToken(ctx context.Context, token string, now time.Time) (*models.User, error) {
+	return r.first(ctx, r.db, "reset_token = ? AND reset_expires_at > ?", token, now)
+}
+
+func (r *gormUserRepository) FindByEmailChangeToken(ctx context.Context, token string, now time.Time) (*models.User, error) {
+	return r.first(ctx, r.db, "email_change_token = ? AND email_change_expires_at > ?", token, now)
+}
+
+func (r *gormUserRepository) UsernameTaken(ctx context.Context, username string, exceptID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("username = ? AND id <> ?", username, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) EmailTaken(ctx context.Context, email string, exceptID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).
+		Where("LOWER(email) = ? AND id <> ?", strings.ToLower(email), exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User) error {
+	if user.Version == 0 {
+		user.Version = 1
+	}
+	return duplicate(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *gormUserRepository) Save(ctx context.Context, user *models.User) error {
+	read := user.Version
+	user.Version++
+	// Unlike gorm's Save, never fall back to an insert when no row matches.
+	result := r.db.WithContext(ctx).Unscoped().Model(user).
+		Where("version = ?", read).
+		Select("*").Updates(user)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrStale
+	}
+	if result.Error != nil {
+		user.Version = read
+		return duplicate(result.Error)
+	}
+	return nil
+}
+
+func (r *gormUserRepository) UpdatePassword(ctx context.Context, user *models.User, hash string) error {
+	return r.updateColumn(ctx, user, "password", hash)
+}
+
+func (r *gormUserRepository) UpdateRole(ctx context.Context, user *models.User, role string) error {
+	return r.updateColumn(ctx, user, "role", role)
+}
+
+func (r *gormUserRepository) updateColumn(ctx context.Context, user *models.User, column string, value interface{}) error {
+	err := r.db.WithContext(ctx).Model(user).Updates(map[string]interface{}{
+		column:    value,
+		"version": gorm.Expr("version + 1"),
+	}).Error
+	if err != nil {
+		return err
+	}
+	user.Version++
+	return nil
+}
+
+func (r *gormUserRepository) RevokeTokens(ctx context.Context, user *models.User, at time.Time) error {
+	if err := r.db.WithContext(ctx).Model(user).UpdateColumn("tokens_valid_after", at).Error; err != nil {
+		return err
+	}
+	user.TokensValidAfter = at
+	return nil
+}
+
+func (r *gormUserRepository) TokensValidAfter(ctx context.Context, id uuid.UUID) (time.Time, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Select("tokens_valid_after").Take(&user, "id = ?", id).Error
+	if err != nil {
+		return time.Time{}, notFound(err)
+	}
+	return user.TokensValidAfter, nil
+}
+
+func (r *gormUserRepository) ListByRole(ctx context.Context, role string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Where("role = ?", role).Order("created_at").Find(&users).Error
+	return users, err
+}
+
+func (r *gormUserRepository) LockByRole(ctx context.Context, role string) ([]models.User, error) {
+	query := r.db.WithContext(ctx)
+	if r.db.Dialector.Name() == "sqlserver" {
+		// SQL Server takes locking hints instead of FOR UPDATE.
+		query = query.Table("users WITH (UPDLOCK, HOLDLOCK)")
+	} else {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var users []models.User
+	err := query.Where("role = ?", role).Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *gormUserRepository) Delete(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Delete(user).Error
+}
+
+func (r *gormUserRepository) FindDeletedBefore(ctx context.Context, cutoff time.Time) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *gormUserRepository) Purge(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(user).Error
+}
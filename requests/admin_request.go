@@ -0,0 +1,21 @@
+package requests
+
+type SetupRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type CreateInvitationRequest struct {
+	Email          string `json:"email" validate:"email,max=254"`
+	ExpiresInHours int    `json:"expires_in_hours" validate:"min=0,max=8760"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user admin superadmin"`
+}
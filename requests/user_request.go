@@ -1,12 +1,62 @@
 package requests
 
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Name     string `json:"name"`
-	Password string `json:"password"`
+	Email      string `json:"email" validate:"required,email,max=254"`
+	Name       string `json:"name" validate:"required,max=100"`
+	Password   string `json:"password" validate:"required"`
+	InviteCode string `json:"invite_code" validate:"max=64"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type UpdateUserRequest struct {
+	Name      string                 `json:"name" validate:"max=100"`
+	Username  string                 `json:"username" validate:"min=3,max=30,regex=^[a-zA-Z0-9_.]+$"`
+	AvatarURL string                 `json:"avatar_url" validate:"max=2048,url"`
+	Bio       string                 `json:"bio" validate:"max=500"`
+	Locale    string                 `json:"locale" validate:"max=35,regex=^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{1,8})*$"`
+	Timezone  string                 `json:"timezone" validate:"max=64,timezone"`
+	Phone     string                 `json:"phone" validate:"regex=^\\+[1-9][0-9]{6,14}$"`
+	Metadata  map[string]interface{} `json:"metadata" validate:"max=50"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+	// RefreshToken optionally names the current session, which is kept
+	// while every other session is signed out.
+	RefreshToken string `json:"refresh_token"`
+}
+
+// DeleteAccountRequest confirms an account deletion with the password, or,
+// for accounts without one, with the token emailed on a first request made
+// without it.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// ChangeEmailRequest authorizes an email change with the password, or, for
+// accounts without one, with the token emailed to the current address on a
+// first request made without it.
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email,max=254"`
 	Password string `json:"password"`
+	Token    string `json:"token"`
 }
@@ -7,27 +7,57 @@ import (
 )
 
 type UserResponse struct {
-	ID         string    `json:"id"`
-	Email      string    `json:"email"`
-	Name       string    `json:"name"`
-	Username   string    `json:"username"`
-	Role       string    `json:"role"`
-	IsVerified bool      `json:"is_verified"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID                 string         `json:"id"`
+	Email              string         `json:"email"`
+	Name               string         `json:"name"`
+	Username           string         `json:"username"`
+	AvatarURL          string         `json:"avatar_url"`
+	AvatarThumbnailURL string         `json:"avatar_thumbnail_url"`
+	Bio                string         `json:"bio"`
+	Locale             string         `json:"locale"`
+	Timezone           string         `json:"timezone"`
+	Phone              string         `json:"phone"`
+	Metadata           models.JSONMap `json:"metadata"`
+	Role               string         `json:"role"`
+	IsVerified         bool           `json:"is_verified"`
+	MustChangePassword bool           `json:"must_change_password"`
+	Version            int64          `json:"version"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+
+	// Impersonation is set when the request was made with an impersonation token.
+	Impersonation *ImpersonationContext `json:"impersonation,omitempty"`
+}
+
+// ImpersonationContext flags a session in which an administrator acts as the user.
+type ImpersonationContext struct {
+	ImpersonatorID string `json:"impersonator_id"`
 }
 
 // Converts a models.User into the public response.
 func ToUserResponse(u models.User) UserResponse {
+	metadata := u.Metadata
+	if metadata == nil {
+		metadata = models.JSONMap{}
+	}
 	return UserResponse{
-		ID:         u.ID.String(),
-		Email:      u.Email,
-		Name:       u.Name,
-		Username:   u.Username,
-		Role:       u.Role,
-		IsVerified: u.IsVerified,
-		CreatedAt:  u.CreatedAt,
-		UpdatedAt:  u.UpdatedAt,
+		ID:                 u.ID.String(),
+		Email:              u.Email,
+		Name:               u.Name,
+		Username:           u.Username,
+		AvatarURL:          u.AvatarURL,
+		AvatarThumbnailURL: u.AvatarThumbnailURL,
+		Bio:                u.Bio,
+		Locale:             u.Locale,
+		Timezone:           u.Timezone,
+		Phone:              u.Phone,
+		Metadata:           metadata,
+		Role:               u.Role,
+		IsVerified:         u.IsVerified,
+		MustChangePassword: u.MustChangePassword,
+		Version:            u.Version,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
 	}
 }
 
@@ -38,3 +68,26 @@ type AuthResponse struct {
 	RefreshToken string       `json:"refresh_token,omitempty"`
 	User         UserResponse `json:"user,omitempty"`
 }
+
+type ImpersonationResponse struct {
+	Status      string       `json:"status"`
+	Message     string       `json:"message"`
+	AccessToken string       `json:"access_token"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	User        UserResponse `json:"user"`
+}
+
+// UserDataExport is the personal data archive returned by /api/user/export.
+type UserDataExport struct {
+	ExportedAt  time.Time             `json:"exported_at"`
+	Profile     UserResponse          `json:"profile"`
+	Sessions    []SessionExport       `json:"sessions"`
+	Identities  []models.UserIdentity `json:"identities"`
+	AuditEvents []models.AuditEvent   `json:"audit_events"`
+}
+
+type SessionExport struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,39 +1,77 @@
 package routes
 
 import (
+	"github.com/ElvinEga/gofiber_starter/container"
 	"github.com/ElvinEga/gofiber_starter/controllers"
 	"github.com/ElvinEga/gofiber_starter/middlewares"
+	"github.com/ElvinEga/gofiber_starter/storage"
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/static"
 )
 
-func SetupRoutes(app *fiber.App) {
-	// Apply security headers and rate limiting globally
+// SetupRoutes registers the middleware and routes of the application built
+// in ctr.
+func SetupRoutes(app *fiber.App, ctr *container.Container) {
+	authController := controllers.NewAuthController(ctr.Auth, ctr.Users)
+	userController := controllers.NewUserController(ctr.Users, ctr.Accounts, ctr.Avatars)
+	adminController := controllers.NewAdminController(ctr.Users, ctr.Invitations, ctr.Impersonation, ctr.Audit)
+	setupController := controllers.NewSetupController(ctr.Bootstrap)
+
+	// Apply security headers, locale negotiation and rate limiting globally
 	app.Use(middlewares.SecurityHeaders())
+	app.Use(middlewares.Locale())
 	app.Use(middlewares.RateLimit())
 
+	// Files kept on the local filesystem are served by the app itself
+	if local, ok := ctr.Storage.(*storage.LocalStorage); ok {
+		app.Get(storage.LocalURLPrefix+"/*", static.New(local.Dir))
+	}
+
 	// API group
 	api := app.Group("/api")
 
+	// First superadmin, when started without bootstrap credentials (public)
+	api.Post("/setup", setupController.CompleteSetup)
+
 	// Auth routes (public)
 	auth := api.Group("/auth")
-	auth.Post("/register", controllers.Register)
-	auth.Post("/login", controllers.Login)
-	auth.Post("/google", controllers.GoogleSSO)
-	auth.Get("/google/callback", controllers.GoogleCallback)
-	auth.Post("/refresh", controllers.RefreshToken)
-	auth.Get("/verify", controllers.VerifyEmail)
-	auth.Post("/forgot-password", controllers.RequestPasswordReset)
-	auth.Post("/reset-password", controllers.ResetPassword)
-
-	// Protected routes
-	protected := api.Group("/", middlewares.JWTProtected())
+	auth.Post("/register", authController.Register)
+	auth.Post("/login", authController.Login)
+	auth.Post("/google", authController.GoogleSSO)
+	auth.Get("/google/callback", authController.GoogleCallback)
+	auth.Post("/refresh", authController.RefreshToken)
+	auth.Get("/verify", authController.VerifyEmail)
+	auth.Get("/confirm-email", authController.ConfirmEmailChange)
+	auth.Post("/forgot-password", authController.RequestPasswordReset)
+	auth.Post("/reset-password", authController.ResetPassword)
+
+	// Protected routes. Users who must change their password can only do
+	// that, read their profile and log out.
+	protected := api.Group("/", middlewares.JWTProtected(ctr.Tokens, ctr.Users), middlewares.RequirePasswordChanged(
+		"PUT /api/user/password",
+		"GET /api/user/profile",
+		"POST /api/logout",
+	))
 
 	// User routes
 	user := protected.Group("/user")
-	user.Get("/profile", controllers.GetUserProfile)
-	user.Put("/profile", controllers.UpdateUser)
-	user.Put("/password", controllers.ChangePassword)
+	user.Get("/profile", userController.GetUserProfile)
+	user.Put("/profile", userController.UpdateUser)
+	user.Patch("/profile", userController.PatchUser)
+	user.Post("/avatar", userController.UploadAvatar)
+	user.Delete("/avatar", userController.DeleteAvatar)
+	user.Put("/password", middlewares.ForbidImpersonation(), userController.ChangePassword)
+	user.Post("/email", middlewares.ForbidImpersonation(), userController.RequestEmailChange)
+	user.Get("/export", userController.ExportUserData)
+	user.Post("/delete", middlewares.ForbidImpersonation(), userController.DeleteAccount)
+
+	// Admin routes
+	admin := protected.Group("/admin", middlewares.RequireRole("superadmin"))
+	admin.Post("/invitations", adminController.CreateInvitation)
+	admin.Post("/users/:id/impersonate", adminController.ImpersonateUser)
+	admin.Put("/users/:id/role", adminController.UpdateUserRole)
+	admin.Get("/audit-events", adminController.ListAuditEvents)
 
 	// Logout route (protected)
-	protected.Post("/logout", controllers.Logout)
+	protected.Post("/logout", authController.Logout)
 }
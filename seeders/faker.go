@@ -0,0 +1,133 @@
+package seeders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/utils"
+)
+
+// The generator draws from these small lists instead of a faker library to
+// keep the module's dependencies down; a seeded PCG and a per-user suffix
+// still give reproducible, unique addresses. Swap in a faker package here if
+// demos need more variety.
+var (
+	firstNames = []string{
+		"Amina", "Ben", "Carla", "Daniel", "Elena", "Farid", "Grace", "Hiro",
+		"Ines", "Jonas", "Kemi", "Liam", "Maya", "Noah", "Olga", "Pablo",
+		"Quinn", "Rosa", "Sami", "Tara", "Umar", "Vera", "Wanjiru", "Xavier",
+		"Yara", "Zoe",
+	}
+	lastNames = []string{
+		"Adeyemi", "Bauer", "Chen", "Dubois", "Evans", "Fischer", "Garcia",
+		"Haddad", "Ivanova", "Jensen", "Kamau", "Larsen", "Moreau", "Nakamura",
+		"Okafor", "Patel", "Rossi", "Silva", "Tanaka", "Underwood", "Novak",
+		"Wang", "Yilmaz", "Zhou",
+	}
+	emailDomains = []string{"example.com", "example.net", "example.org"}
+)
+
+// GenerateOptions tunes GenerateUsers.
+type GenerateOptions struct {
+	Count int
+	// Password of every generated user; defaults to DefaultFakePassword.
+	Password string
+	// Role of every generated user; defaults to user.
+	Role string
+	// Seed makes the generated users reproducible; zero picks a random one.
+	Seed uint64
+	// BatchSize is the number of users inserted per transaction.
+	BatchSize int
+}
+
+// DefaultFakePassword is the password of generated users unless set.
+const DefaultFakePassword = "Password123!"
+
+// GenerateUsers creates Count plausible, verified users for demos and load
+// tests and returns how many were created in committed batches. Generated addresses that already
+// exist are skipped, so the same seed can be run again. It fails with
+// ErrProduction for a production container.
+func GenerateUsers(ctx context.Context, ctr *container.Container, opts GenerateOptions) (int, error) {
+	if err := checkEnvironment(ctr); err != nil {
+		return 0, err
+	}
+	if opts.Count <= 0 {
+		return 0, errors.New("count must be positive")
+	}
+	if opts.Password == "" {
+		opts.Password = DefaultFakePassword
+	}
+	if opts.Role == "" {
+		opts.Role = models.RoleUser
+	}
+	if !models.IsValidRole(opts.Role) {
+		return 0, fmt.Errorf("unknown role %q", opts.Role)
+	}
+	if opts.Seed == 0 {
+		opts.Seed = uint64(time.Now().UnixNano())
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	// One hash for everyone keeps large runs fast.
+	hash, err := ctr.Hasher.Hash(opts.Password)
+	if err != nil {
+		return 0, err
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed>>1|1))
+	created := 0
+	for start := 0; start < opts.Count; start += opts.BatchSize {
+		end := min(start+opts.BatchSize, opts.Count)
+		// Count a batch only once it commits; a rolled back batch creates nobody.
+		batch := 0
+		err := ctr.Store.Transaction(ctx, func(tx repositories.Store) error {
+			for i := start; i < end; i++ {
+				user := fakeUser(rng, i, hash, opts.Role)
+				if _, err := tx.Users().FindByEmailIncludingDeleted(ctx, user.Email); err == nil {
+					continue
+				} else if !errors.Is(err, repositories.ErrNotFound) {
+					return err
+				}
+				if err := tx.Users().Create(ctx, user); err != nil {
+					return err
+				}
+				batch++
+			}
+			return nil
+		})
+		if err != nil {
+			return created, err
+		}
+		created += batch
+	}
+	return created, nil
+}
+
+// fakeUser builds the i-th generated user from rng.
+func fakeUser(rng *rand.Rand, i int, hash, role string) *models.User {
+	first := firstNames[rng.IntN(len(firstNames))]
+	last := lastNames[rng.IntN(len(lastNames))]
+	domain := emailDomains[rng.IntN(len(emailDomains))]
+	tag := rng.Uint32() & 0xffff
+	local := strings.ToLower(fmt.Sprintf("%s.%s.%d%04x", first, last, i+1, tag))
+
+	return &models.User{
+		ID:              utils.GenerateUUID(),
+		Name:            first + " " + last,
+		Email:           local + "@" + domain,
+		Username:        strings.ReplaceAll(local, ".", "_"),
+		Password:        hash,
+		Role:            role,
+		IsVerified:      true,
+		EmailVerifiedAt: time.Now().Add(-time.Duration(rng.IntN(365*24)) * time.Hour),
+	}
+}
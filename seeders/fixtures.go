@@ -0,0 +1,151 @@
+package seeders
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed fixtures
+var embeddedFixtures embed.FS
+
+// EmbeddedFixtures returns the fixtures shipped with the application, one
+// directory per environment.
+func EmbeddedFixtures() fs.FS {
+	sub, _ := fs.Sub(embeddedFixtures, "fixtures")
+	return sub
+}
+
+// Fixtures is the content of a fixture file.
+type Fixtures struct {
+	Users []UserFixture `yaml:"users" json:"users"`
+}
+
+// UserFixture describes an account. Role defaults to user.
+type UserFixture struct {
+	Name     string `yaml:"name" json:"name"`
+	Email    string `yaml:"email" json:"email"`
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+	Role     string `yaml:"role" json:"role"`
+	Verified bool   `yaml:"verified" json:"verified"`
+}
+
+// LoadFixtures reads and merges every .yaml, .yml and .json file in dir of
+// fsys, in file name order.
+func LoadFixtures(fsys fs.FS, dir string) (*Fixtures, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Fixtures{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	merged := &Fixtures{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		var file Fixtures
+		switch strings.ToLower(path.Ext(name)) {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &file)
+		case ".json":
+			err = json.Unmarshal(data, &file)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		merged.Users = append(merged.Users, file.Users...)
+	}
+	return merged, nil
+}
+
+// FixturesSeeder loads the users of fsys/<env> and creates the missing ones.
+// Existing accounts keep their password but get the fixture's name, role and
+// verification state.
+func FixturesSeeder(fsys fs.FS) Seeder {
+	return Seeder{
+		Name:        "fixtures",
+		Description: "users from the environment's fixture files",
+		Run: func(ctx context.Context, ctr *container.Container, env string) error {
+			if err := checkEnvironment(ctr); err != nil {
+				return err
+			}
+			fixtures, err := LoadFixtures(fsys, env)
+			if err != nil {
+				return err
+			}
+			for _, f := range fixtures.Users {
+				if err := seedUser(ctx, ctr.Store, ctr.Hasher, f); err != nil {
+					return fmt.Errorf("user %s: %w", f.Email, err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func seedUser(ctx context.Context, store repositories.Store, hasher utils.PasswordHasher, f UserFixture) error {
+	if f.Email == "" || f.Name == "" {
+		return errors.New("name and email are required")
+	}
+	if f.Role == "" {
+		f.Role = models.RoleUser
+	}
+	if !models.IsValidRole(f.Role) {
+		return fmt.Errorf("unknown role %q", f.Role)
+	}
+
+	existing, err := store.Users().FindByEmailIncludingDeleted(ctx, f.Email)
+	if err == nil {
+		existing.Name = f.Name
+		existing.Role = f.Role
+		existing.IsVerified = f.Verified
+		return store.Users().Save(ctx, existing)
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+
+	if f.Password == "" {
+		return errors.New("password is required for new users")
+	}
+	hash, err := hasher.Hash(f.Password)
+	if err != nil {
+		return err
+	}
+	username := f.Username
+	if username == "" {
+		username = utils.GenerateUsername(f.Name)
+	}
+	return store.Users().Create(ctx, &models.User{
+		ID:         utils.GenerateUUID(),
+		Name:       f.Name,
+		Email:      f.Email,
+		Username:   username,
+		Password:   hash,
+		Role:       f.Role,
+		IsVerified: f.Verified,
+	})
+}
@@ -0,0 +1,145 @@
+// Package seeders fills a database with data for development, tests and
+// demos. Seeders are named, registered in a Registry and safe to run more
+// than once.
+package seeders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ElvinEga/gofiber_starter/container"
+)
+
+// Environments seeders can target.
+const (
+	EnvDev  = "dev"
+	EnvTest = "test"
+	EnvDemo = "demo"
+)
+
+// Environments lists the known seeding environments.
+var Environments = []string{EnvDev, EnvTest, EnvDemo}
+
+// ErrProduction is returned when seeding a container configured with
+// APP_ENV=production: seed data comes with well-known passwords.
+var ErrProduction = errors.New("refusing to seed with APP_ENV=production")
+
+// checkEnvironment refuses to seed a production container.
+func checkEnvironment(ctr *container.Container) error {
+	if ctr.Config.IsProduction() {
+		return ErrProduction
+	}
+	return nil
+}
+
+// Seeder is a named, idempotent unit of seed data.
+type Seeder struct {
+	Name        string
+	Description string
+	// Environments the seeder runs in; empty means every environment.
+	Environments []string
+	Run          func(ctx context.Context, ctr *container.Container, env string) error
+}
+
+// runsIn reports whether the seeder targets env.
+func (s Seeder) runsIn(env string) bool {
+	return len(s.Environments) == 0 || slices.Contains(s.Environments, env)
+}
+
+// Registry holds seeders in registration order.
+type Registry struct {
+	seeders []Seeder
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default returns a registry with the built-in seeders.
+func Default() *Registry {
+	r := NewRegistry()
+	r.MustRegister(FixturesSeeder(EmbeddedFixtures()))
+	r.MustRegister(Seeder{
+		Name:         "demo-users",
+		Description:  "generate 25 fake users",
+		Environments: []string{EnvDemo},
+		Run: func(ctx context.Context, ctr *container.Container, env string) error {
+			// A fixed seed yields the same users, so reruns skip them.
+			_, err := GenerateUsers(ctx, ctr, GenerateOptions{Count: 25, Seed: 1})
+			return err
+		},
+	})
+	return r
+}
+
+// Register adds s, refusing duplicate names.
+func (r *Registry) Register(s Seeder) error {
+	if s.Name == "" || s.Run == nil {
+		return fmt.Errorf("seeder needs a name and a Run function")
+	}
+	if _, ok := r.Find(s.Name); ok {
+		return fmt.Errorf("seeder %q is already registered", s.Name)
+	}
+	r.seeders = append(r.seeders, s)
+	return nil
+}
+
+// MustRegister is Register for built-in seeders, panicking on error.
+func (r *Registry) MustRegister(s Seeder) {
+	if err := r.Register(s); err != nil {
+		panic(err)
+	}
+}
+
+// Find returns the seeder named name.
+func (r *Registry) Find(name string) (Seeder, bool) {
+	for _, s := range r.seeders {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Seeder{}, false
+}
+
+// Seeders returns the registered seeders in registration order.
+func (r *Registry) Seeders() []Seeder {
+	return slices.Clone(r.seeders)
+}
+
+// Run runs the seeders targeting env in registration order, or only the
+// named ones when names are given, and returns the names of those run. It
+// fails with ErrProduction for a production container.
+func (r *Registry) Run(ctx context.Context, ctr *container.Container, env string, names ...string) ([]string, error) {
+	if err := checkEnvironment(ctr); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(Environments, env) {
+		return nil, fmt.Errorf("unknown seed environment %q", env)
+	}
+
+	selected := r.seeders
+	if len(names) > 0 {
+		selected = nil
+		for _, name := range names {
+			s, ok := r.Find(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown seeder %q", name)
+			}
+			selected = append(selected, s)
+		}
+	}
+
+	var ran []string
+	for _, s := range selected {
+		if !s.runsIn(env) {
+			continue
+		}
+		if err := s.Run(ctx, ctr, env); err != nil {
+			return ran, fmt.Errorf("seeder %s: %w", s.Name, err)
+		}
+		ran = append(ran, s.Name)
+	}
+	return ran, nil
+}
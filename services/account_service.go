@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/mailer"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+)
+
+// AccountService covers personal data export and account deletion.
+type AccountService struct {
+	Deps
+}
+
+func NewAccountService(deps Deps) *AccountService {
+	return &AccountService{Deps: deps}
+}
+
+// UserDataExport is everything stored about a user.
+type UserDataExport struct {
+	User        models.User
+	Sessions    []models.RefreshToken
+	Identities  []models.UserIdentity
+	AuditEvents []models.AuditEvent
+}
+
+// ExportData gathers the user's profile, sessions, identities and audit events.
+func (s *AccountService) ExportData(ctx context.Context, userID uuid.UUID) (*UserDataExport, error) {
+	user, err := s.Store.Users().FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperror.NotFound(i18n.ErrUserNotFound)
+	}
+
+	export := &UserDataExport{User: *user}
+	if export.Sessions, err = s.Store.RefreshTokens().ListForUser(ctx, user.ID); err != nil {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	}
+	if export.Identities, err = s.Store.Identities().ListForUser(ctx, user.ID); err != nil {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	}
+	if export.AuditEvents, err = s.Store.AuditEvents().ListForUser(ctx, user.ID); err != nil {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	}
+
+	recordAudit(ctx, s.Store, AuditDataExport, models.AuditSuccess, &user.ID, &user.ID, impersonationMetadata(ctx))
+	return export, nil
+}
+
+// accountDeletionTTL is how long an emailed account deletion confirmation
+// stays valid.
+const accountDeletionTTL = time.Hour
+
+// DeleteAccountInput re-authenticates an account deletion.
+type DeleteAccountInput struct {
+	// Password confirms the deletion of an account that has one.
+	Password string
+	// Token confirms the deletion of an account without a password, such
+	// as one that only signs in with Google. It is emailed on request.
+	Token string
+	// AccessToken is the token the request was made with.
+	AccessToken string
+}
+
+// DeleteAccount re-authenticates the user, revokes all sessions and
+// soft-deletes the account so it is purged once the grace period elapses.
+// Accounts with a password confirm with it. Accounts without one confirm
+// with a token emailed to them: called without a token, DeleteAccount sends
+// it and returns false.
+func (s *AccountService) DeleteAccount(ctx context.Context, userID uuid.UUID, in DeleteAccountInput) (bool, error) {
+	// The emailed token was written moments ago, possibly after a replica
+	// served the read.
+	ctx = database.WithPrimary(ctx)
+	user, err := s.Store.Users().FindByID(ctx, userID)
+	if err != nil {
+		return false, apperror.NotFound(i18n.ErrUserNotFound)
+	}
+
+	switch {
+	case user.Password != "":
+		if !utils.CheckPasswordHash(s.Hasher, in.Password, user.Password) {
+			recordAudit(ctx, s.Store, AuditAccountDelete, models.AuditFailure, &user.ID, &user.ID, nil)
+			return false, apperror.Unauthorized(i18n.ErrIncorrectPassword)
+		}
+	case in.Token == "":
+		return false, s.sendDeletionConfirmation(ctx, user)
+	case user.DeletionToken == "" || time.Now().After(user.DeletionExpiresAt) ||
+		subtle.ConstantTimeCompare([]byte(in.Token), []byte(user.DeletionToken)) != 1:
+		recordAudit(ctx, s.Store, AuditAccountDelete, models.AuditFailure, &user.ID, &user.ID, nil)
+		return false, apperror.Unauthorized(i18n.ErrInvalidConfirmation)
+	}
+
+	var revokeAccess func()
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		var err error
+		if revokeAccess, err = revokeUserSessions(ctx, tx, s.Tokens, user, ""); err != nil {
+			return err
+		}
+		if user.DeletionToken != "" {
+			user.DeletionToken = ""
+			user.DeletionExpiresAt = time.Time{}
+			if err := tx.Users().Save(ctx, user); err != nil {
+				return err
+			}
+		}
+		return tx.Users().Delete(ctx, user)
+	})
+	if err != nil {
+		return false, writeFailure(err, i18n.ErrAccountDeleteFailed, i18n.ErrAccountDeleteFailed)
+	}
+	revokeAccess()
+	// Also blacklist the presented token explicitly: the per-user revocation
+	// spares tokens minted within the same microsecond.
+	if in.AccessToken != "" {
+		_ = s.Tokens.Revoke(in.AccessToken)
+	}
+	recordAudit(ctx, s.Store, AuditAccountDelete, models.AuditSuccess, &user.ID, &user.ID, models.JSONMap{
+		"grace_days": s.Config.DeletionGraceDays,
+	})
+	return true, nil
+}
+
+// sendDeletionConfirmation emails the user a token confirming the deletion
+// of their account.
+func (s *AccountService) sendDeletionConfirmation(ctx context.Context, user *models.User) error {
+	user.DeletionToken = utils.GenerateSecureToken(32)
+	user.DeletionExpiresAt = time.Now().Add(accountDeletionTTL)
+	if err := s.Store.Users().Save(ctx, user); err != nil {
+		return writeFailure(err, i18n.ErrAccountDeleteFailed, i18n.ErrAccountDeleteFailed)
+	}
+
+	link := fmt.Sprintf("%s/confirm-account-deletion?token=%s", s.Config.FrontendURL, user.DeletionToken)
+	if err := mailer.SendTemplate(s.Mailer, user.Email, RequestInfoFrom(ctx).Locale, mailer.TemplateAccountDelete, map[string]string{
+		"Name": user.Name,
+		"Link": link,
+	}); err != nil {
+		return apperror.Internal(i18n.ErrEmailSendFailed, err)
+	}
+	return nil
+}
+
+// PurgeDeletedAccounts permanently removes accounts whose deletion grace period
+// has elapsed. Personal data is dropped with the user row; audit events are kept
+// for accountability but stripped of identifying details.
+func (s *AccountService) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-time.Duration(s.Config.DeletionGraceDays) * 24 * time.Hour)
+
+	users, err := s.Store.Users().FindDeletedBefore(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range users {
+		user := &users[i]
+		err := s.Store.Transaction(ctx, func(tx repositories.Store) error {
+			if err := tx.RefreshTokens().PurgeForUser(ctx, user.ID); err != nil {
+				return err
+			}
+			if err := tx.Identities().DeleteForUser(ctx, user.ID); err != nil {
+				return err
+			}
+			if err := tx.PasswordHistory().DeleteForUser(ctx, user.ID); err != nil {
+				return err
+			}
+			if err := tx.AuditEvents().AnonymizeForUser(ctx, user.ID); err != nil {
+				return err
+			}
+			return tx.Users().Purge(ctx, user)
+		})
+		if err != nil {
+			return purged, err
+		}
+		deleteAvatarFiles(ctx, s.Storage, user.AvatarKey)
+		purged++
+	}
+	return purged, nil
+}
+
+// PurgeExpiredSessions permanently removes refresh tokens that have expired or
+// were revoked, returning how many were removed.
+func (s *AccountService) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	return s.Store.RefreshTokens().PurgeExpired(ctx, time.Now())
+}
+
+// StartPurger runs PurgeDeletedAccounts on the given interval until the
+// process exits.
+func (s *AccountService) StartPurger(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			if n, err := s.PurgeDeletedAccounts(context.Background()); err != nil {
+				log.Printf("account purge failed: %v", err)
+			} else if n > 0 {
+				log.Printf("purged %d deleted accounts", n)
+			}
+		}
+	}()
+}
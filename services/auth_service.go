@@ -1,413 +1,472 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"time"
 
-	"github.com/ElvinEga/gofiber_starter/blacklist"
-	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/apperror"
 	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/mailer"
 	"github.com/ElvinEga/gofiber_starter/models"
-	"github.com/ElvinEga/gofiber_starter/requests"
-	"github.com/ElvinEga/gofiber_starter/responses"
+	"github.com/ElvinEga/gofiber_starter/repositories"
 	"github.com/ElvinEga/gofiber_starter/utils"
-	"github.com/gofiber/fiber/v3"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
-func newAuthResponse(user models.User, accessToken, refreshToken, message string) responses.AuthResponse {
-	return responses.AuthResponse{
-		Status:       "success",
-		Message:      message,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         responses.ToUserResponse(user),
-	}
+// AuthService handles sign-up, sign-in and credential recovery.
+type AuthService struct {
+	Deps
 }
 
-// Register godoc
-// @Summary Register a new user
-// @Description Create a new user account with auto-generated username
-// @Tags Auth
-// @Accept json
-// @Produce json
-// @Param requests.RegisterRequest body requests.RegisterRequest true "Register Request"
-// @Success 201 {object} responses.AuthResponse
-// @Failure 400 {object} responses.AuthResponse
-// @Failure 500 {object} responses.AuthResponse
-// @Router /api/register [post]
-func Register(c fiber.Ctx) error {
-	var req requests.RegisterRequest
-	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Invalid payload",
-		})
+func NewAuthService(deps Deps) *AuthService {
+	return &AuthService{Deps: deps}
+}
+
+type RegisterInput struct {
+	Name       string
+	Email      string
+	Password   string
+	InviteCode string
+}
+
+type LoginInput struct {
+	Email    string
+	Password string
+}
+
+type ResetPasswordInput struct {
+	Token       string
+	NewPassword string
+}
+
+// AuthResult is the outcome of a successful sign-in.
+type AuthResult struct {
+	User         models.User
+	AccessToken  string
+	RefreshToken string
+}
+
+// GoogleSignInResult is the outcome of a Google sign-in.
+type GoogleSignInResult struct {
+	User    models.User
+	Token   string
+	Created bool
+}
+
+// Register creates an account subject to the registration policy and signs
+// the new user in.
+func (s *AuthService) Register(ctx context.Context, in RegisterInput) (*AuthResult, error) {
+	// The uniqueness checks and the sign-in that follows must see the latest
+	// writes.
+	ctx = database.WithPrimary(ctx)
+	invitation, err := checkRegistrationPolicy(ctx, s.Config, s.Store, in.Email, in.InviteCode)
+	if err != nil {
+		recordAudit(ctx, s.Store, AuditRegister, models.AuditFailure, nil, nil, models.JSONMap{"email": in.Email, "reason": err.Error()})
+		return nil, registrationPolicyFailure(err)
 	}
 
-	// Check email uniqueness
-	var existing models.User
-	if err := database.DB.Where("email = ?", req.Email).First(&existing).Error; err == nil {
-		return c.Status(fiber.StatusConflict).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Email already exists",
-		})
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return c.Status(fiber.StatusInternalServerError).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Database error",
-		})
+	// Check email uniqueness, including accounts pending deletion, which
+	// still hold their address.
+	if existing, err := s.Store.Users().FindByEmailIncludingDeleted(ctx, in.Email); err == nil {
+		recordAudit(ctx, s.Store, AuditRegister, models.AuditFailure, nil, &existing.ID, models.JSONMap{"email": in.Email, "reason": "email already exists"})
+		return nil, apperror.Conflict(i18n.ErrEmailExists)
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	}
+
+	if violations, err := validateNewPassword(ctx, s.Config, s.Store, s.Hasher, "password", in.Password, in.Email, in.Name, nil); err != nil {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	} else if len(violations) > 0 {
+		return nil, apperror.Unprocessable(i18n.ErrPasswordPolicy, violations)
+	}
+
+	passwordHash, err := s.Hasher.Hash(in.Password)
+	if err != nil {
+		return nil, apperror.Internal(i18n.ErrPasswordHashFailed, err)
 	}
 
 	// Create user
 	newUser := models.User{
 		ID:         utils.GenerateUUID(),
-		Name:       req.Name,
-		Email:      req.Email,
-		Password:   utils.HashPassword(req.Password),
-		Username:   utils.GenerateUsername(req.Name),
-		Role:       "user",
+		Name:       in.Name,
+		Email:      in.Email,
+		Password:   passwordHash,
+		Username:   utils.GenerateUsername(in.Name),
+		Role:       models.RoleUser,
 		IsVerified: false,
 	}
-	if err := database.DB.Create(&newUser).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Could not create user",
-		})
-	}
-
-	accessToken, refreshToken, err := GenerateTokenPair(&newUser)
+	// The account, its invitation and its first session are stored together,
+	// so a failure part way leaves neither a user without a session nor a
+	// session without a user.
+	var result *AuthResult
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		if err := tx.Users().Create(ctx, &newUser); err != nil {
+			return writeFailure(err, i18n.ErrUserCreateFailed, i18n.ErrEmailExists)
+		}
+		if err := recordPasswordHistory(ctx, s.Config, tx, newUser.ID, newUser.Password); err != nil {
+			return apperror.Internal(i18n.ErrUserCreateFailed, err)
+		}
+		if err := redeemInvitation(ctx, tx, invitation, newUser.ID); err != nil {
+			return err
+		}
+		var err error
+		result, err = signIn(ctx, tx, s.Tokens, &newUser)
+		return err
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Could not generate tokens",
-		})
+		return nil, txFailure(err, i18n.ErrUserCreateFailed, i18n.ErrEmailExists)
 	}
 
-	return c.Status(201).JSON(newAuthResponse(newUser, accessToken, refreshToken, "Registration successful"))
+	recordAudit(ctx, s.Store, AuditRegister, models.AuditSuccess, &newUser.ID, &newUser.ID, nil)
+	return result, nil
 }
 
-// Login godoc
-// @Summary Login a user
-// @Description Login a user with email and password
-// @Tags Auth
-// @Accept json
-// @Produce json
-// @Param requests.LoginRequest body requests.LoginRequest true "Login Request"
-// @Success 200 {object} responses.AuthResponse
-// @Failure 400 {object} responses.AuthResponse
-// @Failure 401 {object} responses.AuthResponse
-// @Failure 500 {object} responses.AuthResponse
-// @Router /api/login [post]
-func Login(c fiber.Ctx) error {
-	var req requests.LoginRequest
-	if err := c.Bind().Body(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Invalid input",
-		})
-	}
+// Login checks the credentials and opens a new session.
+func (s *AuthService) Login(ctx context.Context, in LoginInput) (*AuthResult, error) {
+	user, err := s.Store.Users().FindByEmail(ctx, in.Email)
 
-	user, err := FindUserByEmail(req.Email)
-
-	if err != nil || !utils.CheckPasswordHash(req.Password, user.Password) {
-		return c.Status(fiber.StatusUnauthorized).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Invalid credentials",
-		})
+	if err != nil || !utils.CheckPasswordHash(s.Hasher, in.Password, user.Password) {
+		var targetID *uuid.UUID
+		if err == nil {
+			targetID = &user.ID
+		}
+		recordAudit(ctx, s.Store, AuditLogin, models.AuditFailure, nil, targetID, models.JSONMap{"email": in.Email})
+		return nil, apperror.Unauthorized(i18n.ErrInvalidCredentials)
 	}
 
-	accessToken, refreshToken, err := GenerateTokenPair(user)
+	result, err := signIn(ctx, s.Store, s.Tokens, user)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Could not generate tokens",
-		})
+		return nil, err
 	}
 
-	return c.JSON(newAuthResponse(*user, accessToken, refreshToken, "Login successful"))
-}
-
-func generateJWT(userID uuid.UUID) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+	// Upgrade hashes made with an older algorithm or weaker parameters while
+	// the plain password is at hand.
+	if s.Hasher.NeedsRehash(user.Password) {
+		if rehashed, err := s.Hasher.Hash(in.Password); err == nil {
+			if err := s.Store.Users().UpdatePassword(ctx, user, rehashed); err != nil {
+				log.Printf("password rehash for user %s failed: %v", user.ID, err)
+			}
+		}
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.AppConfig.JWTSecret))
+
+	recordAudit(ctx, s.Store, AuditLogin, models.AuditSuccess, &user.ID, &user.ID, nil)
+	return result, nil
 }
 
-func GoogleLogin(c fiber.Ctx) error {
-	url := utils.GetGoogleOAuthURL()
-	return c.Redirect().Status(fiber.StatusTemporaryRedirect).To(url)
+// GoogleAuthURL returns the Google consent page to redirect the user to.
+func (s *AuthService) GoogleAuthURL() string {
+	return s.Google.AuthCodeURL()
 }
 
-// GoogleCallback handles the callback from Google OAuth.
-func GoogleCallback(c fiber.Ctx) error {
-	code := c.Query("code")
+// GoogleSignIn exchanges an OAuth authorization code for the Google profile
+// and signs the matching user in, creating the account when the
+// registration policy allows it.
+func (s *AuthService) GoogleSignIn(ctx context.Context, code string) (*GoogleSignInResult, error) {
 	if code == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Code not found"})
+		return nil, apperror.BadRequest(i18n.ErrOAuthCodeMissing)
 	}
 
 	// Exchange the code for an access token and fetch user info.
-	userInfo, err := utils.GetGoogleUserInfo(code)
+	userInfo, err := s.Google.UserInfo(ctx, code)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return nil, apperror.New(http.StatusBadGateway, i18n.ErrOAuthFailed).Wrap(err)
 	}
 
 	// Check if a user with this email exists.
-	var user models.User
-	if err := database.DB.Where("email = ?", userInfo.Email).First(&user).Error; err != nil {
-		// If not, create a new user with auto‑generated username.
+	created := false
+	user, err := s.Store.Users().FindByEmailIncludingDeleted(ctx, userInfo.Email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		// If not, create a new user with auto‑generated username, subject to
+		// the same registration policy as the password sign-up.
+		invitation, err := checkRegistrationPolicy(ctx, s.Config, s.Store, userInfo.Email, "")
+		if err != nil {
+			var policyErr *RegistrationPolicyError
+			if errors.As(err, &policyErr) {
+				recordAudit(ctx, s.Store, AuditGoogleLogin, models.AuditFailure, nil, nil, models.JSONMap{"email": userInfo.Email, "reason": policyErr.Code})
+			}
+			return nil, registrationPolicyFailure(err)
+		}
 
-		user = models.User{
+		user = &models.User{
 			ID:         utils.GenerateUUID(),
 			Email:      userInfo.Email,
 			Name:       userInfo.Name,
 			Username:   utils.GenerateUsername(userInfo.Name),
-			Role:       "user",
+			Role:       models.RoleUser,
 			IsVerified: true,
 		}
-		database.DB.Create(&user)
+		fillFromGoogle(user, userInfo)
+		err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+			if err := tx.Users().Create(ctx, user); err != nil {
+				return writeFailure(err, i18n.ErrUserCreateFailed, i18n.ErrEmailExists)
+			}
+			if err := redeemInvitation(ctx, tx, invitation, user.ID); err != nil {
+				return err
+			}
+			if err := linkIdentity(ctx, tx, user.ID, models.ProviderGoogle, userInfo.ID, userInfo.Email); err != nil {
+				return apperror.Internal(i18n.ErrIdentityLinkFailed, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, txFailure(err, i18n.ErrUserCreateFailed, i18n.ErrEmailExists)
+		}
+		created = true
+	} else if err != nil {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	} else if user.DeletedAt.Valid {
+		recordAudit(ctx, s.Store, AuditGoogleLogin, models.AuditFailure, nil, &user.ID, models.JSONMap{"reason": "account deleted"})
+		return nil, apperror.Forbidden(i18n.ErrAccountDeleted)
+	} else if err := linkIdentity(ctx, s.Store, user.ID, models.ProviderGoogle, userInfo.ID, userInfo.Email); err != nil {
+		return nil, apperror.Internal(i18n.ErrIdentityLinkFailed, err)
+	} else if fillFromGoogle(user, userInfo) {
+		// Losing a concurrent profile update here only costs the picture.
+		if err := s.Store.Users().Save(ctx, user); err != nil && !errors.Is(err, repositories.ErrStale) {
+			return nil, apperror.Internal(i18n.ErrDatabase, err)
+		}
 	}
+	recordAudit(ctx, s.Store, AuditGoogleLogin, models.AuditSuccess, &user.ID, &user.ID, models.JSONMap{"created": created})
 
-	token, err := generateJWT(user.ID)
+	// A user who must change their password gets the same restricted token
+	// as after a password login.
+	var token string
+	if user.MustChangePassword {
+		token, err = s.Tokens.GeneratePasswordChangeJWT(user.ID.String(), user.Role)
+	} else {
+		token, err = s.Tokens.GenerateJWT(user.ID.String())
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create token"})
+		return nil, apperror.Internal(i18n.ErrTokenGenerationFailed, err)
 	}
 
-	return c.JSON(fiber.Map{"token": token, "user": user})
+	return &GoogleSignInResult{User: *user, Token: token, Created: created}, nil
 }
 
-// Logout godoc
-// @Summary Logout a user
-// @Description Invalidate the current JWT token by blacklisting it
-// @Tags Auth
-// @Accept json
-// @Produce json
-// @Success 200 {object} responses.AuthResponse
-// @Failure 401 {object} responses.AuthResponse
-// @Router /api/logout [post]
-func Logout(c fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Authorization header not found",
-		})
-	}
-
-	// Expect token in format "Bearer <token>"
-	const bearerPrefix = "Bearer "
-	if len(authHeader) <= len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
-		return c.Status(fiber.StatusBadRequest).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Invalid authorization header",
-		})
+// fillFromGoogle copies the Google picture and locale into the profile
+// fields the user has not set, and reports whether any was copied.
+func fillFromGoogle(user *models.User, info *utils.GoogleUserInfo) bool {
+	before := profileFieldsOf(user)
+	fields := before
+	if fields.AvatarURL == "" {
+		fields.AvatarURL = info.Picture
+	}
+	if fields.Locale == "" {
+		fields.Locale = info.Locale
+	}
+	for _, violation := range fields.validate(profileDiff(before, fields)) {
+		switch violation.Field {
+		case "avatar_url":
+			fields.AvatarURL = before.AvatarURL
+		case "locale":
+			fields.Locale = before.Locale
+		}
 	}
-	tokenStr := authHeader[len(bearerPrefix):]
-
-	// Parse token to extract expiration (using the same secret).
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.AppConfig.JWTSecret), nil
-	})
-	if err != nil || !token.Valid {
-		return c.Status(fiber.StatusBadRequest).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Invalid token",
-		})
+	if len(profileDiff(before, fields)) == 0 {
+		return false
 	}
+	fields.applyTo(user)
+	return true
+}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return c.Status(fiber.StatusBadRequest).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Invalid token claims",
-		})
-	}
-	expFloat, ok := claims["exp"].(float64)
-	if !ok {
-		return c.Status(fiber.StatusBadRequest).JSON(responses.AuthResponse{
-			Status:  "error",
-			Message: "Invalid expiration time",
-		})
+// Logout invalidates the given access token until it expires.
+func (s *AuthService) Logout(ctx context.Context, accessToken string) error {
+	if err := s.Tokens.Revoke(accessToken); err != nil {
+		return apperror.BadRequest(i18n.ErrInvalidToken)
 	}
-	expirationTime := time.Unix(int64(expFloat), 0)
-
-	// Add token to blacklist.
-	blacklist.Add(tokenStr, expirationTime)
-
-	return c.JSON(responses.AuthResponse{
-		Status:  "success",
-		Message: "Logout successful",
-	})
+	actorID := RequestInfoFrom(ctx).UserID
+	recordAudit(ctx, s.Store, AuditLogout, models.AuditSuccess, actorID, actorID, impersonationMetadata(ctx))
+	return nil
 }
 
-func GenerateTokenPair(user *models.User) (string, string, error) {
-	accessToken, err := utils.GenerateJWTRole(user.ID.String(), user.Role)
+// issueTokens creates an access token and a stored refresh token for user.
+func issueTokens(ctx context.Context, store repositories.Store, tokens *utils.TokenService, user *models.User) (string, string, error) {
+	generate := tokens.GenerateJWTRole
+	if user.MustChangePassword {
+		generate = tokens.GeneratePasswordChangeJWT
+	}
+	accessToken, err := generate(user.ID.String(), user.Role)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken()
+	refreshToken, err := tokens.GenerateRefreshToken()
 	if err != nil {
 		return "", "", err
 	}
 
 	// Store refresh token in database
-	refreshUUID := uuid.New()
-	expiresAt := time.Now().Add(time.Hour * 24 * 7) // 7 days
-	database.DB.Create(&models.RefreshToken{
-		ID:        refreshUUID,
+	err = store.RefreshTokens().Create(ctx, &models.RefreshToken{
+		ID:        uuid.New(),
 		UserID:    user.ID,
 		Token:     refreshToken,
-		ExpiresAt: expiresAt,
+		ExpiresAt: time.Now().Add(time.Hour * 24 * 7), // 7 days
 	})
+	if err != nil {
+		return "", "", err
+	}
 
 	return accessToken, refreshToken, nil
 }
 
-func RefreshToken(c fiber.Ctx) error {
-	var req struct {
-		RefreshToken string `json:"refresh_token"`
-	}
-
-	if err := c.Bind().Body(&req); err != nil {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Invalid input")
-	}
-
-	if req.RefreshToken == "" {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Refresh token is required")
+// signIn opens a session for user, storing its refresh token in store.
+func signIn(ctx context.Context, store repositories.Store, tokens *utils.TokenService, user *models.User) (*AuthResult, error) {
+	accessToken, refreshToken, err := issueTokens(ctx, store, tokens, user)
+	if err != nil {
+		return nil, apperror.Internal(i18n.ErrTokenGenerationFailed, err)
 	}
+	return &AuthResult{User: *user, AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
 
-	// Find refresh token in database
-	var refreshToken models.RefreshToken
-	if err := database.DB.Where("token = ? AND expires_at > ?", req.RefreshToken, time.Now()).First(&refreshToken).Error; err != nil {
-		return utils.HandleError(c, fiber.StatusUnauthorized, "Invalid or expired refresh token")
+// Refresh rotates a refresh token, returning a new token pair.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*AuthResult, error) {
+	stored, err := s.Store.RefreshTokens().FindValid(ctx, refreshToken, time.Now())
+	if errors.Is(err, repositories.ErrNotFound) {
+		// A token issued moments ago may not have reached the replicas yet.
+		ctx = database.WithPrimary(ctx)
+		stored, err = s.Store.RefreshTokens().FindValid(ctx, refreshToken, time.Now())
 	}
-
-	// Get user
-	var user models.User
-	if err := database.DB.First(&user, "id = ?", refreshToken.UserID).Error; err != nil {
-		return utils.HandleError(c, fiber.StatusNotFound, "User not found")
+	if err != nil {
+		recordAudit(ctx, s.Store, AuditTokenRefresh, models.AuditFailure, nil, nil, nil)
+		return nil, apperror.Unauthorized(i18n.ErrInvalidRefreshToken)
 	}
 
-	// Generate new token pair
-	accessToken, newRefreshToken, err := GenerateTokenPair(&user)
+	user, err := s.Store.Users().FindByID(ctx, stored.UserID)
 	if err != nil {
-		return utils.HandleError(c, fiber.StatusInternalServerError, "Could not generate tokens")
+		return nil, apperror.NotFound(i18n.ErrUserNotFound)
 	}
 
-	// Delete old refresh token
-	database.DB.Delete(&refreshToken)
-
-	return c.JSON(fiber.Map{
-		"status":        "success",
-		"message":       "Token refreshed successfully",
-		"access_token":  accessToken,
-		"refresh_token": newRefreshToken,
-		"user":          responses.ToUserResponse(user),
+	// Revoke the old token before issuing the new one, in one transaction:
+	// a token presented twice at once yields a single new session, and a
+	// failure leaves the old token usable.
+	var result *AuthResult
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		if err := tx.RefreshTokens().Delete(ctx, stored); err != nil {
+			return err
+		}
+		var err error
+		result, err = signIn(ctx, tx, s.Tokens, user)
+		return err
 	})
+	if errors.Is(err, repositories.ErrNotFound) {
+		recordAudit(ctx, s.Store, AuditTokenRefresh, models.AuditFailure, &user.ID, &user.ID, models.JSONMap{"reason": "token already rotated"})
+		return nil, apperror.Unauthorized(i18n.ErrInvalidRefreshToken)
+	} else if err != nil {
+		return nil, txFailure(err, i18n.ErrTokenGenerationFailed, i18n.ErrTokenGenerationFailed)
+	}
+	recordAudit(ctx, s.Store, AuditTokenRefresh, models.AuditSuccess, &user.ID, &user.ID, nil)
+	return result, nil
 }
 
-func VerifyEmail(c fiber.Ctx) error {
-	token := c.Query("token")
+// VerifyEmail marks the account holding the verification token as verified.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
 	if token == "" {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Verification token is required")
+		return apperror.BadRequest(i18n.ErrVerificationTokenNeeded)
 	}
 
-	var user models.User
-	if err := database.DB.Where("verification_token = ?", token).First(&user).Error; err != nil {
-		return utils.HandleError(c, fiber.StatusNotFound, "Invalid verification token")
+	user, err := s.Store.Users().FindByVerificationToken(ctx, token)
+	if err != nil {
+		return apperror.NotFound(i18n.ErrInvalidVerification)
 	}
 
 	user.IsVerified = true
 	user.EmailVerifiedAt = time.Now()
 	user.VerificationToken = ""
-	database.DB.Save(&user)
-
-	return c.JSON(fiber.Map{
-		"status":  "success",
-		"message": "Email verified successfully",
-	})
-}
-
-func RequestPasswordReset(c fiber.Ctx) error {
-	var req struct {
-		Email string `json:"email"`
-	}
-
-	if err := c.Bind().Body(&req); err != nil {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Invalid input")
-	}
-
-	if req.Email == "" {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Email is required")
+	if err := s.Store.Users().Save(ctx, user); err != nil {
+		return writeFailure(err, i18n.ErrDatabase, i18n.ErrDatabase)
 	}
+	recordAudit(ctx, s.Store, AuditEmailVerify, models.AuditSuccess, &user.ID, &user.ID, nil)
+	return nil
+}
 
-	user, err := FindUserByEmail(req.Email)
+// RequestPasswordReset emails a reset link when the address is registered.
+// It reports success either way so callers cannot probe for accounts.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.Store.Users().FindByEmail(ctx, email)
 	if err != nil {
+		recordAudit(ctx, s.Store, AuditPasswordResetRequest, models.AuditFailure, nil, nil, models.JSONMap{"email": email})
 		// Don't reveal if email exists
-		return c.JSON(fiber.Map{
-			"status":  "success",
-			"message": "If your email is registered, you will receive a password reset link",
-		})
+		return nil
 	}
 
 	resetToken := utils.GenerateSecureToken(32)
-	resetExpiresAt := time.Now().Add(time.Hour) // 1 hour expiration
-
 	user.ResetToken = resetToken
-	user.ResetExpiresAt = resetExpiresAt
-	database.DB.Save(user)
+	user.ResetExpiresAt = time.Now().Add(time.Hour) // 1 hour expiration
+	if err := s.Store.Users().Save(ctx, user); err != nil {
+		return writeFailure(err, i18n.ErrDatabase, i18n.ErrDatabase)
+	}
+	recordAudit(ctx, s.Store, AuditPasswordResetRequest, models.AuditSuccess, nil, &user.ID, nil)
 
 	// Generate reset link
-	resetLink := fmt.Sprintf("%s/reset-password?token=%s", config.AppConfig.FrontendURL, resetToken)
-
-	// In a real app, send email with resetLink
-	fmt.Printf("Password reset link: %s\n", resetLink)
-
-	return c.JSON(fiber.Map{
-		"status":  "success",
-		"message": "If your email is registered, you will receive a password reset link",
-	})
+	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.Config.FrontendURL, resetToken)
+	if err := mailer.SendTemplate(s.Mailer, user.Email, RequestInfoFrom(ctx).Locale, mailer.TemplatePasswordReset, map[string]string{
+		"Name": user.Name,
+		"Link": resetLink,
+	}); err != nil {
+		log.Printf("password reset email to %s failed: %v", user.Email, err)
+	}
+	return nil
 }
 
-func ResetPassword(c fiber.Ctx) error {
-	var req struct {
-		Token       string `json:"token"`
-		NewPassword string `json:"new_password"`
-	}
-
-	if err := c.Bind().Body(&req); err != nil {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Invalid input")
+// ResetPassword sets a new password using an emailed reset token and signs
+// out every session.
+func (s *AuthService) ResetPassword(ctx context.Context, in ResetPasswordInput) error {
+	user, err := s.Store.Users().FindByResetToken(ctx, in.Token, time.Now())
+	if err != nil {
+		recordAudit(ctx, s.Store, AuditPasswordReset, models.AuditFailure, nil, nil, nil)
+		return apperror.Unauthorized(i18n.ErrInvalidResetToken)
 	}
 
-	if req.Token == "" || req.NewPassword == "" {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Token and new password are required")
+	if violations, err := validateNewPassword(ctx, s.Config, s.Store, s.Hasher, "new_password", in.NewPassword, user.Email, user.Name, user); err != nil {
+		return apperror.Internal(i18n.ErrDatabase, err)
+	} else if len(violations) > 0 {
+		return apperror.Unprocessable(i18n.ErrPasswordPolicy, violations)
 	}
 
-	var user models.User
-	if err := database.DB.Where("reset_token = ? AND reset_expires_at > ?", req.Token, time.Now()).First(&user).Error; err != nil {
-		return utils.HandleError(c, fiber.StatusUnauthorized, "Invalid or expired reset token")
+	passwordHash, err := s.Hasher.Hash(in.NewPassword)
+	if err != nil {
+		return apperror.Internal(i18n.ErrPasswordHashFailed, err)
 	}
 
-	user.Password = utils.HashPassword(req.NewPassword)
+	user.Password = passwordHash
+	user.MustChangePassword = false
 	user.ResetToken = ""
 	user.ResetExpiresAt = time.Time{}
-	database.DB.Save(&user)
-
-	return c.JSON(fiber.Map{
-		"status":  "success",
-		"message": "Password reset successfully",
+	var revokeAccess func()
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		if err := tx.Users().Save(ctx, user); err != nil {
+			return err
+		}
+		if err := recordPasswordHistory(ctx, s.Config, tx, user.ID, user.Password); err != nil {
+			return err
+		}
+		var err error
+		revokeAccess, err = revokeUserSessions(ctx, tx, s.Tokens, user, "")
+		return err
 	})
+	if err != nil {
+		return writeFailure(err, i18n.ErrPasswordResetFailed, i18n.ErrPasswordResetFailed)
+	}
+	revokeAccess()
+	notifyPasswordChanged(ctx, s.Mailer, user)
+	recordAudit(ctx, s.Store, AuditPasswordReset, models.AuditSuccess, &user.ID, &user.ID, nil)
+	return nil
 }
 
-func FindUserByEmail(email string) (*models.User, error) {
-	var user models.User
-	err := database.DB.First(&user, "email = ?", email).Error
-	return &user, err
+// linkIdentity records the external account a user signed in with, if not already known.
+func linkIdentity(ctx context.Context, store repositories.Store, userID uuid.UUID, provider, subject, email string) error {
+	if subject == "" {
+		return nil
+	}
+	return store.Identities().Link(ctx, &models.UserIdentity{
+		ID:       utils.GenerateUUID(),
+		UserID:   userID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	})
 }
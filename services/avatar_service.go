@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/storage"
+	"github.com/ElvinEga/gofiber_starter/uploads"
+	"github.com/google/uuid"
+)
+
+// AvatarService stores the avatar images users upload.
+type AvatarService struct {
+	Deps
+}
+
+func NewAvatarService(deps Deps) *AvatarService {
+	return &AvatarService{Deps: deps}
+}
+
+type UploadAvatarInput struct {
+	File io.Reader
+	// IfVersion is as in UpdateProfileInput.
+	IfVersion int64
+}
+
+// Upload checks that the file is an image within the size limit, stores a
+// square avatar and thumbnail made from it and points the profile at them.
+// The previously uploaded avatar, if any, is deleted.
+func (s *AvatarService) Upload(ctx context.Context, userID uuid.UUID, in UploadAvatarInput) (*models.User, error) {
+	// The version checked against If-Match, and saved against, must be the
+	// latest one, not a replica's.
+	ctx = database.WithPrimary(ctx)
+	user, err := s.Store.Users().FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperror.NotFound(i18n.ErrUserNotFound)
+	}
+	if in.IfVersion != 0 && in.IfVersion != user.Version {
+		return nil, apperror.PreconditionFailed(i18n.ErrPreconditionFailed)
+	}
+
+	data, err := uploads.ReadLimited(in.File, int64(s.Config.AvatarMaxBytes))
+	if errors.Is(err, uploads.ErrTooLarge) {
+		return nil, apperror.New(http.StatusRequestEntityTooLarge, i18n.ErrFileTooLarge).
+			WithDetails(map[string]interface{}{"max_bytes": s.Config.AvatarMaxBytes})
+	} else if err != nil {
+		return nil, apperror.BadRequest(i18n.ErrInvalidPayload).Wrap(err)
+	}
+	if _, err := uploads.Sniff(data, uploads.ImageTypes...); err != nil {
+		return nil, apperror.New(http.StatusUnsupportedMediaType, i18n.ErrUnsupportedFileType).Wrap(err)
+	}
+	img, format, err := uploads.DecodeImage(data)
+	if err != nil {
+		return nil, apperror.Unprocessable(i18n.ErrInvalidImage, nil).Wrap(err)
+	}
+
+	avatar, contentType, ext, err := uploads.Encode(uploads.Square(img, s.Config.AvatarSize), format)
+	if err != nil {
+		return nil, apperror.Internal(i18n.ErrUploadFailed, err)
+	}
+	thumbnail, _, _, err := uploads.Encode(uploads.Square(img, s.Config.AvatarThumbnailSize), format)
+	if err != nil {
+		return nil, apperror.Internal(i18n.ErrUploadFailed, err)
+	}
+
+	// A new key per upload keeps caches from serving the old image.
+	key := "avatars/" + user.ID.String() + "/" + uuid.NewString() + ext
+	if err := s.Storage.Put(ctx, key, bytes.NewReader(avatar), int64(len(avatar)), contentType); err != nil {
+		return nil, apperror.Internal(i18n.ErrUploadFailed, err)
+	}
+	thumbnailKey := avatarThumbnailKey(key)
+	if err := s.Storage.Put(ctx, thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), contentType); err != nil {
+		deleteAvatarFiles(ctx, s.Storage, key)
+		return nil, apperror.Internal(i18n.ErrUploadFailed, err)
+	}
+
+	previousKey, previousURL := user.AvatarKey, user.AvatarURL
+	user.AvatarKey = key
+	user.AvatarURL = s.Storage.URL(key)
+	user.AvatarThumbnailURL = s.Storage.URL(thumbnailKey)
+	if err := s.saveAvatar(ctx, user, in.IfVersion); err != nil {
+		deleteAvatarFiles(ctx, s.Storage, key)
+		return nil, err
+	}
+	deleteAvatarFiles(ctx, s.Storage, previousKey)
+
+	s.audit(ctx, AuditAvatarUpload, user, previousURL)
+	return user, nil
+}
+
+// Delete removes the avatar from the profile, and its files when it was
+// uploaded.
+func (s *AvatarService) Delete(ctx context.Context, userID uuid.UUID, ifVersion int64) (*models.User, error) {
+	// The version checked against If-Match, and saved against, must be the
+	// latest one, not a replica's.
+	ctx = database.WithPrimary(ctx)
+	user, err := s.Store.Users().FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperror.NotFound(i18n.ErrUserNotFound)
+	}
+	if ifVersion != 0 && ifVersion != user.Version {
+		return nil, apperror.PreconditionFailed(i18n.ErrPreconditionFailed)
+	}
+	if user.AvatarURL == "" && user.AvatarKey == "" {
+		return user, nil
+	}
+
+	previousKey, previousURL := user.AvatarKey, user.AvatarURL
+	user.AvatarKey, user.AvatarURL, user.AvatarThumbnailURL = "", "", ""
+	if err := s.saveAvatar(ctx, user, ifVersion); err != nil {
+		return nil, err
+	}
+	deleteAvatarFiles(ctx, s.Storage, previousKey)
+
+	s.audit(ctx, AuditAvatarDelete, user, previousURL)
+	return user, nil
+}
+
+func (s *AvatarService) saveAvatar(ctx context.Context, user *models.User, ifVersion int64) error {
+	err := s.Store.Users().Save(ctx, user)
+	if err != nil && ifVersion != 0 && errors.Is(err, repositories.ErrStale) {
+		return apperror.PreconditionFailed(i18n.ErrPreconditionFailed)
+	} else if err != nil {
+		return writeFailure(err, i18n.ErrDatabase, i18n.ErrDatabase)
+	}
+	return nil
+}
+
+func (s *AvatarService) audit(ctx context.Context, action string, user *models.User, previousURL string) {
+	metadata := models.JSONMap{"changes": models.JSONMap{
+		"avatar_url": models.JSONMap{"from": previousURL, "to": user.AvatarURL},
+	}}
+	for key, value := range impersonationMetadata(ctx) {
+		metadata[key] = value
+	}
+	recordAudit(ctx, s.Store, action, models.AuditSuccess, &user.ID, &user.ID, metadata)
+}
+
+// avatarThumbnailKey returns the key of the thumbnail stored with the avatar
+// under key.
+func avatarThumbnailKey(key string) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_thumb" + ext
+}
+
+// deleteAvatarFiles removes an uploaded avatar and its thumbnail. Failures
+// are only logged: by then the profile no longer points at the files, and a
+// leftover file does no harm.
+func deleteAvatarFiles(ctx context.Context, store storage.Storage, key string) {
+	if key == "" {
+		return
+	}
+	for _, k := range []string{key, avatarThumbnailKey(key)} {
+		if err := store.Delete(ctx, k); err != nil {
+			log.Printf("failed to delete avatar file %s: %v", k, err)
+		}
+	}
+}
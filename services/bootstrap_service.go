@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/utils"
+)
+
+// defaultAdminPasswords are credentials shipped by earlier versions of the
+// seeder. A superadmin still using one is refused in production.
+var defaultAdminPasswords = []string{"admin1234"}
+
+// ErrDefaultCredentials is returned by Bootstrap in production while a
+// superadmin, or the configured bootstrap password, uses a default password.
+var ErrDefaultCredentials = errors.New("default superadmin credentials are still active")
+
+// ErrDefaultJWTSecret is returned by Bootstrap in production while the JWT
+// signing secret is the built-in default.
+var ErrDefaultJWTSecret = errors.New("JWT_SECRET is the built-in default")
+
+// ErrUnknownRegistrationMode is returned by Bootstrap when REGISTRATION_MODE
+// is not one of the accepted modes, rather than falling back to open
+// registration.
+var ErrUnknownRegistrationMode = errors.New("unknown REGISTRATION_MODE")
+
+// BootstrapService creates the first superadmin, either from configured
+// credentials or through a one-time setup token.
+type BootstrapService struct {
+	Deps
+	users *UserService
+
+	mu         sync.Mutex
+	setupToken string
+}
+
+func NewBootstrapService(deps Deps, users *UserService) *BootstrapService {
+	return &BootstrapService{Deps: deps, users: users}
+}
+
+type CompleteSetupInput struct {
+	Token    string
+	Name     string
+	Email    string
+	Password string
+}
+
+// BootstrapResult reports what Bootstrap did. At most one field is set.
+type BootstrapResult struct {
+	// Created is the superadmin made from the configured credentials.
+	Created *models.User
+	// SetupToken must be presented to CompleteSetup to create the first
+	// superadmin. It lives in memory only and changes on every start.
+	SetupToken string
+}
+
+// Bootstrap runs at startup. When no superadmin exists it creates one from
+// the BOOTSTRAP_ADMIN_* configuration, who must change the password on first
+// login, or otherwise issues a setup token. It fails on an unknown
+// registration mode and, in production, while default credentials are in
+// use.
+func (s *BootstrapService) Bootstrap(ctx context.Context) (*BootstrapResult, error) {
+	switch s.Config.RegistrationMode {
+	case config.RegistrationOpen, config.RegistrationInviteOnly, config.RegistrationClosed:
+	default:
+		return nil, fmt.Errorf("%w %q (want %s, %s or %s)", ErrUnknownRegistrationMode, s.Config.RegistrationMode,
+			config.RegistrationOpen, config.RegistrationInviteOnly, config.RegistrationClosed)
+	}
+	if s.Config.IsProduction() {
+		if s.Config.JWTSecret == "" || s.Config.JWTSecret == "secret" {
+			return nil, ErrDefaultJWTSecret
+		}
+		if isDefaultAdminPassword(s.Config.BootstrapAdminPassword) {
+			return nil, ErrDefaultCredentials
+		}
+	}
+
+	admins, err := s.Store.Users().ListByRole(ctx, models.RoleSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if len(admins) > 0 {
+		if s.Config.IsProduction() {
+			for _, admin := range admins {
+				for _, password := range defaultAdminPasswords {
+					if utils.CheckPasswordHash(s.Hasher, password, admin.Password) {
+						return nil, ErrDefaultCredentials
+					}
+				}
+			}
+		}
+		return &BootstrapResult{}, nil
+	}
+
+	if s.Config.BootstrapAdminEmail != "" && s.Config.BootstrapAdminPassword != "" {
+		user, err := s.users.CreateUser(ctx, CreateUserInput{
+			Name:               s.Config.BootstrapAdminName,
+			Email:              s.Config.BootstrapAdminEmail,
+			Password:           s.Config.BootstrapAdminPassword,
+			Role:               models.RoleSuperAdmin,
+			MustChangePassword: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &BootstrapResult{Created: user}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setupToken = utils.GenerateSecureToken(24)
+	return &BootstrapResult{SetupToken: s.setupToken}, nil
+}
+
+// CompleteSetup creates the first superadmin with the password they chose,
+// consuming the setup token issued by Bootstrap.
+func (s *BootstrapService) CompleteSetup(ctx context.Context, in CompleteSetupInput) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.setupToken == "" {
+		return nil, apperror.NotFound(i18n.ErrSetupUnavailable)
+	}
+	if subtle.ConstantTimeCompare([]byte(in.Token), []byte(s.setupToken)) != 1 {
+		recordAudit(ctx, s.Store, AuditBootstrap, models.AuditFailure, nil, nil, models.JSONMap{"reason": "invalid setup token"})
+		return nil, apperror.Forbidden(i18n.ErrInvalidSetupToken)
+	}
+	// A superadmin may have been created another way, e.g. from the CLI.
+	if admins, err := s.Store.Users().ListByRole(ctx, models.RoleSuperAdmin); err != nil {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	} else if len(admins) > 0 {
+		s.setupToken = ""
+		return nil, apperror.NotFound(i18n.ErrSetupUnavailable)
+	}
+
+	user, err := s.users.CreateUser(ctx, CreateUserInput{
+		Name:     in.Name,
+		Email:    in.Email,
+		Password: in.Password,
+		Role:     models.RoleSuperAdmin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.setupToken = ""
+	recordAudit(ctx, s.Store, AuditBootstrap, models.AuditSuccess, &user.ID, &user.ID, nil)
+	return user, nil
+}
+
+func isDefaultAdminPassword(password string) bool {
+	for _, p := range defaultAdminPasswords {
+		if password == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/google/uuid"
+)
+
+// RequestInfo describes who is calling a service and from where. Transport
+// adapters attach it to the context; services use it for audit events,
+// emails and impersonation checks.
+type RequestInfo struct {
+	IP        string
+	UserAgent string
+	Locale    string
+	// UserID is the authenticated user, nil for anonymous calls.
+	UserID *uuid.UUID
+	// ImpersonatorID is set when the call is made with an impersonation token.
+	ImpersonatorID string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo returns a copy of ctx carrying info.
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom returns the RequestInfo attached to ctx, defaulting the
+// locale for calls made outside a request (CLI, background jobs).
+func RequestInfoFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	if info.Locale == "" {
+		info.Locale = i18n.DefaultLocale
+	}
+	return info
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/mailer"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/storage"
+	"github.com/ElvinEga/gofiber_starter/utils"
+)
+
+// Deps are the collaborators shared by every service. They are built once by
+// the application container; nothing in this package reads globals.
+type Deps struct {
+	Config  config.Config
+	Store   repositories.Store
+	Tokens  *utils.TokenService
+	Mailer  mailer.Mailer
+	Google  *utils.GoogleOAuth
+	Storage storage.Storage
+	Hasher  utils.PasswordHasher
+}
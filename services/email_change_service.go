@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/mailer"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+)
+
+const emailChangeTTL = 24 * time.Hour
+
+// emailChangeAuthTTL is how long an emailed authorization of the email
+// change of an account without a password stays valid.
+const emailChangeAuthTTL = time.Hour
+
+var errEmailTaken = errors.New("email already in use")
+
+type ChangeEmailInput struct {
+	NewEmail string
+	// Password authorizes the change for an account that has one.
+	Password string
+	// Token authorizes the change for an account without a password, such
+	// as one that only signs in with Google. It is emailed on request.
+	Token string
+}
+
+// RequestEmailChange stores the new address as pending and sends a
+// confirmation link to it, plus a notice to the current address. Accounts
+// with a password authorize the change with it. Accounts without one
+// authorize it with a token emailed to the current address: called without
+// a token, RequestEmailChange sends it and returns false.
+func (s *UserService) RequestEmailChange(ctx context.Context, userID uuid.UUID, in ChangeEmailInput) (bool, error) {
+	// The emailed token was written moments ago, possibly after a replica
+	// served the read.
+	ctx = database.WithPrimary(ctx)
+	user, err := s.Profile(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user.Password != "" && !utils.CheckPasswordHash(s.Hasher, in.Password, user.Password) {
+		recordAudit(ctx, s.Store, AuditEmailChangeRequest, models.AuditFailure, &user.ID, &user.ID, nil)
+		return false, apperror.Unauthorized(i18n.ErrIncorrectPassword)
+	}
+	if strings.EqualFold(in.NewEmail, user.Email) {
+		return false, apperror.BadRequest(i18n.ErrEmailUnchanged)
+	}
+	if err := checkEmailDomain(s.Config, in.NewEmail); err != nil {
+		return false, registrationPolicyFailure(err)
+	}
+	if taken, err := s.Store.Users().EmailTaken(ctx, in.NewEmail, user.ID); err != nil {
+		return false, apperror.Internal(i18n.ErrDatabase, err)
+	} else if taken {
+		return false, apperror.Conflict(i18n.ErrEmailExists)
+	}
+
+	if user.Password == "" {
+		switch {
+		case in.Token == "":
+			return false, s.sendEmailChangeAuthorization(ctx, user, in.NewEmail)
+		// The token only authorizes the address it was sent for.
+		case user.EmailChangeAuthToken == "" || time.Now().After(user.EmailChangeAuthExpiresAt) ||
+			subtle.ConstantTimeCompare([]byte(in.Token), []byte(user.EmailChangeAuthToken)) != 1 ||
+			!strings.EqualFold(in.NewEmail, user.PendingEmail):
+			recordAudit(ctx, s.Store, AuditEmailChangeRequest, models.AuditFailure, &user.ID, &user.ID, nil)
+			return false, apperror.Unauthorized(i18n.ErrInvalidConfirmation)
+		}
+		user.EmailChangeAuthToken = ""
+		user.EmailChangeAuthExpiresAt = time.Time{}
+	}
+
+	user.PendingEmail = in.NewEmail
+	user.EmailChangeToken = utils.GenerateSecureToken(32)
+	user.EmailChangeExpiresAt = time.Now().Add(emailChangeTTL)
+	if err := s.Store.Users().Save(ctx, user); err != nil {
+		return false, writeFailure(err, i18n.ErrEmailChangeFailed, i18n.ErrEmailExists)
+	}
+
+	locale := RequestInfoFrom(ctx).Locale
+	link := fmt.Sprintf("%s/confirm-email?token=%s", s.Config.FrontendURL, user.EmailChangeToken)
+	if err := mailer.SendTemplate(s.Mailer, in.NewEmail, locale, mailer.TemplateEmailChangeConfirm, map[string]string{
+		"Name": user.Name,
+		"Link": link,
+	}); err != nil {
+		return false, apperror.Internal(i18n.ErrEmailSendFailed, err)
+	}
+	if err := mailer.SendTemplate(s.Mailer, user.Email, locale, mailer.TemplateEmailChangeNotice, map[string]string{
+		"Name":     user.Name,
+		"NewEmail": in.NewEmail,
+	}); err != nil {
+		log.Printf("email change notice to %s failed: %v", user.Email, err)
+	}
+
+	recordAudit(ctx, s.Store, AuditEmailChangeRequest, models.AuditSuccess, &user.ID, &user.ID, models.JSONMap{"new_email": in.NewEmail})
+	return true, nil
+}
+
+// sendEmailChangeAuthorization emails the current address of an account
+// without a password a token authorizing the change to newEmail. The
+// address is kept as pending; any earlier confirmation link is dropped so
+// it cannot confirm the new address before the change is authorized.
+func (s *UserService) sendEmailChangeAuthorization(ctx context.Context, user *models.User, newEmail string) error {
+	user.PendingEmail = newEmail
+	user.EmailChangeToken = ""
+	user.EmailChangeExpiresAt = time.Time{}
+	user.EmailChangeAuthToken = utils.GenerateSecureToken(32)
+	user.EmailChangeAuthExpiresAt = time.Now().Add(emailChangeAuthTTL)
+	if err := s.Store.Users().Save(ctx, user); err != nil {
+		return writeFailure(err, i18n.ErrEmailChangeFailed, i18n.ErrEmailExists)
+	}
+
+	link := fmt.Sprintf("%s/authorize-email-change?token=%s", s.Config.FrontendURL, user.EmailChangeAuthToken)
+	if err := mailer.SendTemplate(s.Mailer, user.Email, RequestInfoFrom(ctx).Locale, mailer.TemplateEmailChangeAuth, map[string]string{
+		"Name":     user.Name,
+		"NewEmail": newEmail,
+		"Link":     link,
+	}); err != nil {
+		return apperror.Internal(i18n.ErrEmailSendFailed, err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange swaps the pending email in using the token sent to the
+// new address.
+func (s *UserService) ConfirmEmailChange(ctx context.Context, token string) (*models.User, error) {
+	if token == "" {
+		return nil, apperror.BadRequest(i18n.ErrConfirmationTokenNeeded)
+	}
+
+	user, err := s.Store.Users().FindByEmailChangeToken(ctx, token, time.Now())
+	if err != nil {
+		return nil, apperror.NotFound(i18n.ErrInvalidConfirmation)
+	}
+	previousEmail := user.Email
+
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		// The address may have been claimed since the change was requested.
+		if taken, err := tx.Users().EmailTaken(ctx, user.PendingEmail, user.ID); err != nil {
+			return err
+		} else if taken {
+			return errEmailTaken
+		}
+
+		user.Email = user.PendingEmail
+		user.PendingEmail = ""
+		user.EmailChangeToken = ""
+		user.EmailChangeExpiresAt = time.Time{}
+		user.IsVerified = true
+		user.EmailVerifiedAt = time.Now()
+		// Reset links were sent to the previous address.
+		user.ResetToken = ""
+		user.ResetExpiresAt = time.Time{}
+		return tx.Users().Save(ctx, user)
+	})
+	if errors.Is(err, errEmailTaken) {
+		return nil, apperror.Conflict(i18n.ErrEmailExists)
+	} else if err != nil {
+		return nil, writeFailure(err, i18n.ErrEmailChangeFailed, i18n.ErrEmailExists)
+	}
+
+	recordAudit(ctx, s.Store, AuditEmailChange, models.AuditSuccess, &user.ID, &user.ID, models.JSONMap{
+		"from": previousEmail,
+		"to":   user.Email,
+	})
+	return user, nil
+}
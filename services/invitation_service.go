@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+)
+
+// InvitationService issues registration invitations.
+type InvitationService struct {
+	Deps
+}
+
+func NewInvitationService(deps Deps) *InvitationService {
+	return &InvitationService{Deps: deps}
+}
+
+type CreateInvitationInput struct {
+	// Email, when set, binds the invitation to that address.
+	Email string
+	// ExpiresInHours defaults to the configured invitation TTL.
+	ExpiresInHours int
+}
+
+// Create issues an invitation code on behalf of the calling user.
+func (s *InvitationService) Create(ctx context.Context, in CreateInvitationInput) (*models.Invitation, error) {
+	ttl := in.ExpiresInHours
+	if ttl <= 0 {
+		ttl = s.Config.InvitationTTLHours
+	}
+
+	var createdBy uuid.UUID
+	if userID := RequestInfoFrom(ctx).UserID; userID != nil {
+		createdBy = *userID
+	}
+	invitation := models.Invitation{
+		ID:        utils.GenerateUUID(),
+		Code:      utils.GenerateSecureToken(16),
+		Email:     in.Email,
+		CreatedBy: createdBy,
+		ExpiresAt: time.Now().Add(time.Duration(ttl) * time.Hour),
+	}
+	if err := s.Store.Invitations().Create(ctx, &invitation); err != nil {
+		return nil, apperror.Internal(i18n.ErrInvitationCreateFailed, err)
+	}
+	return &invitation, nil
+}
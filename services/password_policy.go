@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/passwordpolicy"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+)
+
+// validateNewPassword checks password against the configured policy and, for
+// existing users, against their recent password history.
+func validateNewPassword(ctx context.Context, cfg config.Config, store repositories.Store, hasher utils.PasswordHasher, field, password, email, name string, user *models.User) ([]utils.ValidationError, error) {
+	policy := passwordpolicy.FromConfig(cfg)
+	errs := policy.Validate(field, password, email, name)
+	if len(errs) > 0 || user == nil || policy.HistorySize <= 0 {
+		return errs, nil
+	}
+
+	hashes := []string{user.Password}
+	history, err := store.PasswordHistory().Recent(ctx, user.ID, policy.HistorySize)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range history {
+		hashes = append(hashes, entry.Hash)
+	}
+
+	for _, hash := range hashes {
+		if hash != "" && utils.CheckPasswordHash(hasher, password, hash) {
+			return []utils.ValidationError{
+				utils.NewValidationError(field, i18n.PasswordReused, nil),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// recordPasswordHistory stores hash as the user's latest password and drops
+// entries beyond the configured history size.
+func recordPasswordHistory(ctx context.Context, cfg config.Config, store repositories.Store, userID uuid.UUID, hash string) error {
+	size := cfg.PasswordHistorySize
+	if size <= 0 {
+		return nil
+	}
+	return store.PasswordHistory().Add(ctx, &models.PasswordHistory{
+		ID:     utils.GenerateUUID(),
+		UserID: userID,
+		Hash:   hash,
+	}, size)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/utils"
+)
+
+// maxMetadataSize bounds the encoded size of a user's metadata.
+const maxMetadataSize = 16 << 10
+
+// profileFields are the profile fields users may change themselves. They are
+// the whitelist for profile patches: the patch is applied to a document of
+// these fields, and any other member in the result is refused.
+type profileFields struct {
+	Name      string         `json:"name" validate:"max=100"`
+	Username  string         `json:"username" validate:"required,min=3,max=30,regex=^[a-zA-Z0-9_.]+$"`
+	AvatarURL string         `json:"avatar_url" validate:"max=2048,url"`
+	Bio       string         `json:"bio" validate:"max=500"`
+	Locale    string         `json:"locale" validate:"max=35,regex=^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{1,8})*$"`
+	Timezone  string         `json:"timezone" validate:"max=64,timezone"`
+	Phone     string         `json:"phone" validate:"regex=^\\+[1-9][0-9]{6,14}$"`
+	Metadata  models.JSONMap `json:"metadata" validate:"max=50"`
+}
+
+// profileFieldsOf returns the user's current profile. Metadata is never
+// nil, so a JSON Patch can add members to it.
+func profileFieldsOf(user *models.User) profileFields {
+	metadata := user.Metadata
+	if metadata == nil {
+		metadata = models.JSONMap{}
+	}
+	return profileFields{
+		Name:      user.Name,
+		Username:  user.Username,
+		AvatarURL: user.AvatarURL,
+		Bio:       user.Bio,
+		Locale:    user.Locale,
+		Timezone:  user.Timezone,
+		Phone:     user.Phone,
+		Metadata:  metadata,
+	}
+}
+
+func (f profileFields) applyTo(user *models.User) {
+	user.Name = f.Name
+	user.Username = f.Username
+	user.AvatarURL = f.AvatarURL
+	user.Bio = f.Bio
+	user.Locale = f.Locale
+	user.Timezone = f.Timezone
+	user.Phone = f.Phone
+	user.Metadata = f.Metadata
+	if len(user.Metadata) == 0 {
+		user.Metadata = nil
+	}
+}
+
+// validate checks the fields named in changes. Fields that are not being
+// changed are left alone, so a value stored before a rule existed does not
+// block updates to other fields.
+func (f profileFields) validate(changes models.JSONMap) []utils.ValidationError {
+	var violations []utils.ValidationError
+	for _, violation := range utils.NewValidator().Validate(&f).Errors {
+		root := strings.FieldsFunc(violation.Field, func(r rune) bool { return r == '.' || r == '[' })[0]
+		if _, changed := changes[root]; changed {
+			violations = append(violations, violation)
+		}
+	}
+	if _, changed := changes["metadata"]; changed {
+		if encoded, err := json.Marshal(f.Metadata); err != nil || len(encoded) > maxMetadataSize {
+			violations = append(violations, utils.NewValidationError("metadata", i18n.ValidationMaxLength, map[string]interface{}{"max": maxMetadataSize}))
+		}
+	}
+	return violations
+}
+
+// profileDiff returns the fields that differ between before and after as
+// {"field": {"from": old, "to": new}}, for the audit log.
+func profileDiff(before, after profileFields) models.JSONMap {
+	changes := models.JSONMap{}
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < b.NumField(); i++ {
+		from, to := b.Field(i).Interface(), a.Field(i).Interface()
+		if !reflect.DeepEqual(from, to) {
+			changes[jsonName(b.Type().Field(i))] = models.JSONMap{"from": from, "to": to}
+		}
+	}
+	return changes
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+// decodeProfileFields decodes a patched profile document. Members outside
+// the whitelist and values of the wrong type are reported per field with 422.
+func decodeProfileFields(doc []byte) (profileFields, error) {
+	var fields profileFields
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &members); err != nil {
+		return fields, apperror.BadRequest(i18n.ErrInvalidPatch).Wrap(err)
+	}
+
+	allowed := map[string]bool{}
+	typ := reflect.TypeOf(fields)
+	for i := 0; i < typ.NumField(); i++ {
+		allowed[jsonName(typ.Field(i))] = true
+	}
+	var violations []utils.ValidationError
+	for name := range members {
+		if !allowed[name] {
+			violations = append(violations, utils.NewValidationError(name, i18n.ValidationReadOnly, nil))
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+	if len(violations) > 0 {
+		return fields, apperror.Unprocessable(i18n.ErrValidationFailed, violations)
+	}
+
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			violations := []utils.ValidationError{utils.NewValidationError(typeErr.Field, i18n.ValidationInvalid, nil)}
+			return fields, apperror.Unprocessable(i18n.ErrValidationFailed, violations)
+		}
+		return fields, apperror.BadRequest(i18n.ErrInvalidPatch).Wrap(err)
+	}
+	if fields.Metadata == nil {
+		fields.Metadata = models.JSONMap{}
+	}
+	return fields, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/google/uuid"
+)
+
+// Registration policy error codes returned to clients.
+const (
+	CodeRegistrationClosed    = i18n.ErrRegistrationClosed
+	CodeInvitationRequired    = i18n.ErrInvitationRequired
+	CodeInvitationInvalid     = i18n.ErrInvitationInvalid
+	CodeEmailDomainNotAllowed = i18n.ErrEmailDomainNotAllowed
+	CodeDisposableEmail       = i18n.ErrDisposableEmail
+)
+
+// RegistrationPolicyError describes why a sign-up was refused.
+type RegistrationPolicyError struct {
+	Code string
+}
+
+func (e *RegistrationPolicyError) Error() string {
+	return i18n.T(i18n.DefaultLocale, e.Code, nil)
+}
+
+// disposableEmailDomains is a small built-in list of throwaway mail providers,
+// enforced when BLOCK_DISPOSABLE_EMAILS is enabled.
+var disposableEmailDomains = map[string]bool{
+	"10minutemail.com":  true,
+	"discard.email":     true,
+	"dispostable.com":   true,
+	"fakeinbox.com":     true,
+	"getnada.com":       true,
+	"guerrillamail.com": true,
+	"mailinator.com":    true,
+	"maildrop.cc":       true,
+	"sharklasers.com":   true,
+	"temp-mail.org":     true,
+	"tempmail.com":      true,
+	"throwawaymail.com": true,
+	"trashmail.com":     true,
+	"yopmail.com":       true,
+}
+
+// checkRegistrationPolicy validates a new account against the configured
+// registration mode and email domain rules. In invite-only mode it returns the
+// invitation that authorises the sign-up, which must be redeemed once the user
+// has been created.
+func checkRegistrationPolicy(ctx context.Context, cfg config.Config, store repositories.Store, email, inviteCode string) (*models.Invitation, error) {
+	if cfg.RegistrationMode == config.RegistrationClosed {
+		return nil, &RegistrationPolicyError{Code: CodeRegistrationClosed}
+	}
+
+	if err := checkEmailDomain(cfg, email); err != nil {
+		return nil, err
+	}
+
+	if cfg.RegistrationMode != config.RegistrationInviteOnly {
+		return nil, nil
+	}
+	return findInvitation(ctx, store, email, inviteCode)
+}
+
+// checkEmailDomain applies the allowed, blocked and disposable domain rules.
+func checkEmailDomain(cfg config.Config, email string) error {
+	domain := emailDomain(email)
+	if len(cfg.AllowedEmailDomains) > 0 && !containsDomain(cfg.AllowedEmailDomains, domain) {
+		return &RegistrationPolicyError{Code: CodeEmailDomainNotAllowed}
+	}
+	if containsDomain(cfg.BlockedEmailDomains, domain) {
+		return &RegistrationPolicyError{Code: CodeEmailDomainNotAllowed}
+	}
+	if cfg.BlockDisposableEmails && disposableEmailDomains[domain] {
+		return &RegistrationPolicyError{Code: CodeDisposableEmail}
+	}
+	return nil
+}
+
+// findInvitation looks up a usable invitation by code, or by email when no
+// code was supplied (e.g. Google sign-up).
+func findInvitation(ctx context.Context, store repositories.Store, email, code string) (*models.Invitation, error) {
+	var invitation *models.Invitation
+	var err error
+	if code != "" {
+		invitation, err = store.Invitations().FindUsableByCode(ctx, code, time.Now())
+	} else {
+		invitation, err = store.Invitations().FindUsableByEmail(ctx, email, time.Now())
+	}
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return nil, err
+		}
+		if code == "" {
+			return nil, &RegistrationPolicyError{Code: CodeInvitationRequired}
+		}
+		return nil, &RegistrationPolicyError{Code: CodeInvitationInvalid}
+	}
+
+	if invitation.Email != "" && !strings.EqualFold(invitation.Email, email) {
+		return nil, &RegistrationPolicyError{Code: CodeInvitationInvalid}
+	}
+	return invitation, nil
+}
+
+// redeemInvitation marks an invitation as used by the given user. An
+// invitation another sign-up redeemed in the meantime is refused as invalid,
+// so the caller's transaction rolls back.
+func redeemInvitation(ctx context.Context, store repositories.Store, invitation *models.Invitation, userID uuid.UUID) error {
+	if invitation == nil {
+		return nil
+	}
+	err := store.Invitations().MarkUsed(ctx, invitation, userID, time.Now())
+	if errors.Is(err, repositories.ErrInvitationUsed) {
+		return apperror.Forbidden(CodeInvitationInvalid)
+	} else if err != nil {
+		return apperror.Internal(i18n.ErrInvitationRedeemFailed, err)
+	}
+	return nil
+}
+
+// registrationPolicyFailure maps a refused sign-up to a 403 carrying its
+// policy code.
+func registrationPolicyFailure(err error) error {
+	var policyErr *RegistrationPolicyError
+	if errors.As(err, &policyErr) {
+		return apperror.Forbidden(policyErr.Code)
+	}
+	return apperror.Internal(i18n.ErrDatabase, err)
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(email[at+1:]))
+}
+
+func containsDomain(domains []string, domain string) bool {
+	for _, d := range domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
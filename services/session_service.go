@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/mailer"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+)
+
+// revokeUserSessions deletes the user's refresh tokens, except keepRefreshToken
+// when given, and stores the cutoff that rejects every access token issued so
+// far, which every server process checks. It returns the same revocation for
+// this process's blacklist, for the caller to run once its transaction has
+// committed: the blacklist lives in memory and would not roll back with the
+// database.
+func revokeUserSessions(ctx context.Context, store repositories.Store, tokens *utils.TokenService, user *models.User, keepRefreshToken string) (func(), error) {
+	if err := store.RefreshTokens().DeleteForUser(ctx, user.ID, keepRefreshToken); err != nil {
+		return nil, err
+	}
+	// Token issue times have microsecond precision.
+	if err := store.Users().RevokeTokens(ctx, user, time.Now().Truncate(time.Microsecond)); err != nil {
+		return nil, err
+	}
+	return func() { tokens.RevokeUser(user.ID.String()) }, nil
+}
+
+// TokensValidAfter returns the time before which the user's access tokens
+// were revoked, zero when they never were. A user who no longer exists is
+// unauthorized.
+func (s *UserService) TokensValidAfter(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	at, err := s.Store.Users().TokensValidAfter(ctx, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return time.Time{}, apperror.Unauthorized(i18n.ErrUnauthorized)
+	} else if err != nil {
+		return time.Time{}, apperror.Internal(i18n.ErrDatabase, err)
+	}
+	return at, nil
+}
+
+// notifyPasswordChanged tells the user their password was changed so an
+// unexpected change can be reported.
+func notifyPasswordChanged(ctx context.Context, m mailer.Mailer, user *models.User) {
+	info := RequestInfoFrom(ctx)
+	err := mailer.SendTemplate(m, user.Email, info.Locale, mailer.TemplatePasswordChanged, map[string]string{
+		"Name": user.Name,
+		"Time": time.Now().UTC().Format(time.RFC1123),
+		"IP":   info.IP,
+	})
+	if err != nil {
+		log.Printf("password change notice to %s failed: %v", user.Email, err)
+	}
+}
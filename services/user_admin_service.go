@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+)
+
+// CreateUserInput describes an account created by an operator rather than
+// through sign-up.
+type CreateUserInput struct {
+	Name     string
+	Email    string
+	Password string
+	Role     string
+	// MustChangePassword makes the user replace the password on first login.
+	MustChangePassword bool
+}
+
+// FindByEmail returns the user with the given email address.
+func (s *UserService) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.Store.Users().FindByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return nil, apperror.NotFound(i18n.ErrUserNotFound)
+	}
+	return user, nil
+}
+
+// CreateUser adds a verified account with the given role. The registration
+// policy does not apply, the password policy does.
+func (s *UserService) CreateUser(ctx context.Context, in CreateUserInput) (*models.User, error) {
+	if in.Role == "" {
+		in.Role = models.RoleUser
+	}
+	if !models.IsValidRole(in.Role) {
+		return nil, apperror.BadRequest(i18n.ErrInvalidRole)
+	}
+
+	if _, err := s.Store.Users().FindByEmailIncludingDeleted(ctx, in.Email); err == nil {
+		return nil, apperror.Conflict(i18n.ErrEmailExists)
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	}
+
+	if violations, err := validateNewPassword(ctx, s.Config, s.Store, s.Hasher, "password", in.Password, in.Email, in.Name, nil); err != nil {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	} else if len(violations) > 0 {
+		return nil, apperror.Unprocessable(i18n.ErrPasswordPolicy, violations)
+	}
+
+	passwordHash, err := s.Hasher.Hash(in.Password)
+	if err != nil {
+		return nil, apperror.Internal(i18n.ErrPasswordHashFailed, err)
+	}
+
+	user := models.User{
+		ID:                 utils.GenerateUUID(),
+		Name:               in.Name,
+		Email:              in.Email,
+		Password:           passwordHash,
+		Username:           utils.GenerateUsername(in.Name),
+		Role:               in.Role,
+		IsVerified:         true,
+		MustChangePassword: in.MustChangePassword,
+	}
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		if err := tx.Users().Create(ctx, &user); err != nil {
+			return err
+		}
+		return recordPasswordHistory(ctx, s.Config, tx, user.ID, user.Password)
+	})
+	if err != nil {
+		return nil, writeFailure(err, i18n.ErrUserCreateFailed, i18n.ErrEmailExists)
+	}
+
+	recordAudit(ctx, s.Store, AuditUserCreate, models.AuditSuccess, RequestInfoFrom(ctx).UserID, &user.ID, models.JSONMap{"role": user.Role})
+	return &user, nil
+}
+
+// SetPassword replaces the user's password without the current one, as an
+// operator-initiated reset, and signs out every session. The user must choose
+// a new password on their next login.
+func (s *UserService) SetPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	user, err := s.Profile(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if violations, err := validateNewPassword(ctx, s.Config, s.Store, s.Hasher, "password", password, user.Email, user.Name, user); err != nil {
+		return apperror.Internal(i18n.ErrDatabase, err)
+	} else if len(violations) > 0 {
+		return apperror.Unprocessable(i18n.ErrPasswordPolicy, violations)
+	}
+
+	passwordHash, err := s.Hasher.Hash(password)
+	if err != nil {
+		return apperror.Internal(i18n.ErrPasswordHashFailed, err)
+	}
+
+	user.Password = passwordHash
+	user.MustChangePassword = true
+	var revokeAccess func()
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		if err := tx.Users().Save(ctx, user); err != nil {
+			return err
+		}
+		if err := recordPasswordHistory(ctx, s.Config, tx, user.ID, user.Password); err != nil {
+			return err
+		}
+		var err error
+		revokeAccess, err = revokeUserSessions(ctx, tx, s.Tokens, user, "")
+		return err
+	})
+	if err != nil {
+		return writeFailure(err, i18n.ErrPasswordUpdateFailed, i18n.ErrPasswordUpdateFailed)
+	}
+	revokeAccess()
+
+	recordAudit(ctx, s.Store, AuditPasswordSet, models.AuditSuccess, RequestInfoFrom(ctx).UserID, &user.ID, nil)
+	notifyPasswordChanged(ctx, s.Mailer, user)
+	return nil
+}
+
+// RevokeSessions signs the user out everywhere.
+func (s *UserService) RevokeSessions(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.Profile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	revokeAccess, err := revokeUserSessions(ctx, s.Store, s.Tokens, user, "")
+	if err != nil {
+		return apperror.Internal(i18n.ErrDatabase, err)
+	}
+	revokeAccess()
+	recordAudit(ctx, s.Store, AuditSessionsRevoke, models.AuditSuccess, RequestInfoFrom(ctx).UserID, &user.ID, nil)
+	return nil
+}
@@ -1,104 +1,335 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
 	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/i18n"
 	"github.com/ElvinEga/gofiber_starter/models"
-	"github.com/ElvinEga/gofiber_starter/responses"
+	"github.com/ElvinEga/gofiber_starter/repositories"
 	"github.com/ElvinEga/gofiber_starter/utils"
-	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
 )
 
-// Profile godoc
-// @Summary Get user profile
-// @Description Retrieve the user's profile information
-// @Tags User
-// @Accept json
-// @Produce json
-// @Success 200 {object} responses.UserResponse
-// @Failure 401 {object} responses.UserResponse
-// @Router /api/profile [get]
-func GetUserProfile(c fiber.Ctx) error {
-	userId := c.Locals("userID").(string)
-	var user models.User
-
-	if err := database.DB.First(&user, "id = ?", userId).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
-	}
-	return c.JSON(responses.ToUserResponse(user))
+// UserService manages the signed-in user's own account and, for admins,
+// other users' roles.
+type UserService struct {
+	Deps
 }
 
-func UpdateUser(c fiber.Ctx) error {
-	userID := c.Locals("userID").(string)
-	var updateData struct {
-		Name     string `json:"name"`
-		Username string `json:"username"`
-	}
+func NewUserService(deps Deps) *UserService {
+	return &UserService{Deps: deps}
+}
+
+type UpdateProfileInput struct {
+	Name      string
+	Username  string
+	AvatarURL string
+	Bio       string
+	Locale    string
+	Timezone  string
+	Phone     string
+	// Metadata, when not nil, replaces the stored metadata.
+	Metadata models.JSONMap
+	// IfVersion, when not zero, is the version the client last read. The
+	// update is refused when the profile has changed since.
+	IfVersion int64
+}
 
-	if err := c.Bind().Body(&updateData); err != nil {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Invalid input")
+// PatchFormat is the format of a profile patch document.
+type PatchFormat int
+
+const (
+	// MergePatch is JSON Merge Patch (RFC 7396).
+	MergePatch PatchFormat = iota
+	// JSONPatch is JSON Patch (RFC 6902).
+	JSONPatch
+)
+
+type PatchProfileInput struct {
+	Format PatchFormat
+	Patch  []byte
+	// IfVersion is as in UpdateProfileInput.
+	IfVersion int64
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string
+	NewPassword     string
+	// RefreshToken, when it belongs to the user, names the session to keep.
+	RefreshToken string
+}
+
+// ChangePasswordResult carries a fresh access token when a session was kept.
+type ChangePasswordResult struct {
+	AccessToken string
+}
+
+// Profile returns the user with the given ID.
+func (s *UserService) Profile(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.Store.Users().FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperror.NotFound(i18n.ErrUserNotFound)
 	}
+	return user, nil
+}
 
-	var user models.User
-	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
-		return utils.HandleError(c, fiber.StatusNotFound, "User not found")
+// UpdateProfile changes the non-empty fields of in. Concurrent updates do
+// not overwrite each other: the one that loses fails with 409, or with 412
+// when in.IfVersion was given.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uuid.UUID, in UpdateProfileInput) (*models.User, error) {
+	// The version checked against If-Match, and saved against, must be the
+	// latest one, not a replica's.
+	ctx = database.WithPrimary(ctx)
+	user, err := s.Profile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if in.IfVersion != 0 && in.IfVersion != user.Version {
+		return nil, apperror.PreconditionFailed(i18n.ErrPreconditionFailed)
 	}
 
 	// Update allowed fields only
-	if updateData.Name != "" {
-		user.Name = updateData.Name
-	}
-	if updateData.Username != "" {
-		// Check if username is already taken
-		var existingUser models.User
-		if err := database.DB.Where("username = ? AND id != ?", updateData.Username, userID).First(&existingUser).Error; err == nil {
-			return utils.HandleError(c, fiber.StatusConflict, "Username already taken")
-		}
-		user.Username = updateData.Username
+	fields := profileFieldsOf(user)
+	if in.Name != "" {
+		fields.Name = in.Name
 	}
+	if in.Username != "" {
+		fields.Username = in.Username
+	}
+	if in.AvatarURL != "" {
+		fields.AvatarURL = in.AvatarURL
+	}
+	if in.Bio != "" {
+		fields.Bio = in.Bio
+	}
+	if in.Locale != "" {
+		fields.Locale = in.Locale
+	}
+	if in.Timezone != "" {
+		fields.Timezone = in.Timezone
+	}
+	if in.Phone != "" {
+		fields.Phone = in.Phone
+	}
+	if in.Metadata != nil {
+		fields.Metadata = in.Metadata
+	}
+	return s.saveProfile(ctx, user, fields, in.IfVersion)
+}
 
-	database.DB.Save(&user)
-	return c.JSON(fiber.Map{
-		"status":  "success",
-		"message": "User updated successfully",
-		"data":    responses.ToUserResponse(user),
-	})
+// PatchProfile applies a JSON Merge Patch or JSON Patch document to the
+// user's mutable profile fields. The patch may only touch those fields, and
+// the patched profile must pass their validation rules; otherwise nothing
+// is changed and the failures are reported per field.
+func (s *UserService) PatchProfile(ctx context.Context, userID uuid.UUID, in PatchProfileInput) (*models.User, error) {
+	// The version checked against If-Match, and saved against, must be the
+	// latest one, not a replica's.
+	ctx = database.WithPrimary(ctx)
+	user, err := s.Profile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if in.IfVersion != 0 && in.IfVersion != user.Version {
+		return nil, apperror.PreconditionFailed(i18n.ErrPreconditionFailed)
+	}
+
+	doc, err := json.Marshal(profileFieldsOf(user))
+	if err != nil {
+		return nil, apperror.Internal(i18n.ErrInternal, err)
+	}
+	switch in.Format {
+	case JSONPatch:
+		doc, err = utils.ApplyJSONPatch(doc, in.Patch)
+	default:
+		doc, err = utils.ApplyMergePatch(doc, in.Patch)
+	}
+	if errors.Is(err, utils.ErrPatchTestFailed) {
+		return nil, apperror.Conflict(i18n.ErrPatchTestFailed).Wrap(err)
+	} else if err != nil {
+		return nil, apperror.BadRequest(i18n.ErrInvalidPatch).Wrap(err)
+	}
+
+	fields, err := decodeProfileFields(doc)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveProfile(ctx, user, fields, in.IfVersion)
 }
 
-func ChangePassword(c fiber.Ctx) error {
-	userID := c.Locals("userID").(string)
-	var req struct {
-		CurrentPassword string `json:"current_password"`
-		NewPassword     string `json:"new_password"`
+// saveProfile checks and stores the fields that differ from user's and
+// records what changed in the audit log. Nothing is written when no field
+// changes.
+func (s *UserService) saveProfile(ctx context.Context, user *models.User, fields profileFields, ifVersion int64) (*models.User, error) {
+	changes := profileDiff(profileFieldsOf(user), fields)
+	if len(changes) == 0 {
+		return user, nil
+	}
+	if violations := fields.validate(changes); len(violations) > 0 {
+		return nil, apperror.Unprocessable(i18n.ErrValidationFailed, violations)
+	}
+	if _, changed := changes["username"]; changed {
+		if taken, err := s.Store.Users().UsernameTaken(ctx, fields.Username, user.ID); err != nil {
+			return nil, apperror.Internal(i18n.ErrDatabase, err)
+		} else if taken {
+			return nil, apperror.Conflict(i18n.ErrUsernameTaken)
+		}
+	}
+
+	// An avatar_url set by hand replaces an uploaded avatar.
+	uploadedAvatar := ""
+	if _, changed := changes["avatar_url"]; changed {
+		uploadedAvatar = user.AvatarKey
+		user.AvatarKey, user.AvatarThumbnailURL = "", ""
+	}
+
+	fields.applyTo(user)
+	if err := s.Store.Users().Save(ctx, user); err != nil {
+		if ifVersion != 0 && errors.Is(err, repositories.ErrStale) {
+			return nil, apperror.PreconditionFailed(i18n.ErrPreconditionFailed)
+		}
+		// The username may have been taken since the check above.
+		return nil, writeFailure(err, i18n.ErrDatabase, i18n.ErrUsernameTaken)
+	}
+	deleteAvatarFiles(ctx, s.Storage, uploadedAvatar)
+
+	metadata := models.JSONMap{"changes": changes}
+	for key, value := range impersonationMetadata(ctx) {
+		metadata[key] = value
 	}
+	recordAudit(ctx, s.Store, AuditProfileUpdate, models.AuditSuccess, &user.ID, &user.ID, metadata)
+	return user, nil
+}
 
-	if err := c.Bind().Body(&req); err != nil {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Invalid input")
+// ChangePassword replaces the password after checking the current one and
+// signs out every other session.
+func (s *UserService) ChangePassword(ctx context.Context, userID uuid.UUID, in ChangePasswordInput) (*ChangePasswordResult, error) {
+	// The version saved against, and the password checked, must be the
+	// latest ones, not a replica's.
+	ctx = database.WithPrimary(ctx)
+	user, err := s.Profile(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	if req.CurrentPassword == "" || req.NewPassword == "" {
-		return utils.HandleError(c, fiber.StatusBadRequest, "Current password and new password are required")
+	if !utils.CheckPasswordHash(s.Hasher, in.CurrentPassword, user.Password) {
+		recordAudit(ctx, s.Store, AuditPasswordChange, models.AuditFailure, &user.ID, &user.ID, nil)
+		return nil, apperror.Unauthorized(i18n.ErrIncorrectCurrentPass)
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
-		return utils.HandleError(c, fiber.StatusNotFound, "User not found")
+	if violations, err := validateNewPassword(ctx, s.Config, s.Store, s.Hasher, "new_password", in.NewPassword, user.Email, user.Name, user); err != nil {
+		return nil, apperror.Internal(i18n.ErrDatabase, err)
+	} else if len(violations) > 0 {
+		return nil, apperror.Unprocessable(i18n.ErrPasswordPolicy, violations)
 	}
 
-	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
-		return utils.HandleError(c, fiber.StatusUnauthorized, "Incorrect current password")
+	passwordHash, err := s.Hasher.Hash(in.NewPassword)
+	if err != nil {
+		return nil, apperror.Internal(i18n.ErrPasswordHashFailed, err)
 	}
 
-	user.Password = utils.HashPassword(req.NewPassword)
-	database.DB.Save(&user)
+	keepRefreshToken := ""
+	if in.RefreshToken != "" {
+		if current, err := s.Store.RefreshTokens().FindValidForUser(ctx, in.RefreshToken, user.ID, time.Now()); err == nil {
+			keepRefreshToken = current.Token
+		}
+	}
 
-	return c.JSON(fiber.Map{
-		"status":  "success",
-		"message": "Password updated successfully",
+	user.Password = passwordHash
+	user.MustChangePassword = false
+	var revokeAccess func()
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		if err := tx.Users().Save(ctx, user); err != nil {
+			return err
+		}
+		if err := recordPasswordHistory(ctx, s.Config, tx, user.ID, user.Password); err != nil {
+			return err
+		}
+		var err error
+		revokeAccess, err = revokeUserSessions(ctx, tx, s.Tokens, user, keepRefreshToken)
+		return err
 	})
+	if err != nil {
+		return nil, writeFailure(err, i18n.ErrPasswordUpdateFailed, i18n.ErrPasswordUpdateFailed)
+	}
+	revokeAccess()
+	recordAudit(ctx, s.Store, AuditPasswordChange, models.AuditSuccess, &user.ID, &user.ID, models.JSONMap{
+		"kept_current_session": keepRefreshToken != "",
+	})
+	notifyPasswordChanged(ctx, s.Mailer, user)
+
+	result := &ChangePasswordResult{}
+	// The presented access token was revoked with the others; hand the kept
+	// session a fresh one.
+	if keepRefreshToken != "" {
+		accessToken, err := s.Tokens.GenerateJWTRole(user.ID.String(), user.Role)
+		if err != nil {
+			return nil, apperror.Internal(i18n.ErrTokenGenerationFailed, err)
+		}
+		result.AccessToken = accessToken
+	}
+	return result, nil
 }
 
-func GetUserByID(id string) (*models.User, error) {
-	var user models.User
-	err := database.DB.First(&user, "id = ?", id).Error
-	return &user, err
+// UpdateRole assigns role to the target user. Callers cannot change their
+// own role, and the last superadmin cannot be demoted. The user's sessions are
+// ended, since the old role is embedded in the access tokens issued so far.
+func (s *UserService) UpdateRole(ctx context.Context, targetID uuid.UUID, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, apperror.BadRequest(i18n.ErrInvalidRole)
+	}
+	user, err := s.Store.Users().FindByID(ctx, targetID)
+	if err != nil {
+		return nil, apperror.NotFound(i18n.ErrUserNotFound)
+	}
+
+	actorID := RequestInfoFrom(ctx).UserID
+	if actorID != nil && *actorID == user.ID {
+		recordAudit(ctx, s.Store, AuditRoleChange, models.AuditFailure, actorID, &user.ID, models.JSONMap{"role": role, "reason": "self role change"})
+		return nil, apperror.BadRequest(i18n.ErrRoleChangeSelf)
+	}
+
+	previousRole := user.Role
+	var revokeAccess func()
+	err = s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		if previousRole == models.RoleSuperAdmin && role != models.RoleSuperAdmin {
+			// Locking the superadmins makes concurrent demotions wait for
+			// each other, so they cannot both see another superadmin left.
+			superadmins, err := tx.Users().LockByRole(ctx, models.RoleSuperAdmin)
+			if err != nil {
+				return apperror.Internal(i18n.ErrRoleUpdateFailed, err)
+			}
+			others := 0
+			for _, admin := range superadmins {
+				if admin.ID != user.ID {
+					others++
+				}
+			}
+			if others == 0 {
+				return apperror.Conflict(i18n.ErrLastSuperadmin)
+			}
+		}
+		if err := tx.Users().UpdateRole(ctx, user, role); err != nil {
+			return apperror.Internal(i18n.ErrRoleUpdateFailed, err)
+		}
+		var err error
+		revokeAccess, err = revokeUserSessions(ctx, tx, s.Tokens, user, "")
+		return err
+	})
+	if err != nil {
+		if appErr, ok := apperror.As(err); ok && appErr.Code == i18n.ErrLastSuperadmin {
+			recordAudit(ctx, s.Store, AuditRoleChange, models.AuditFailure, actorID, &user.ID, models.JSONMap{"role": role, "reason": "last superadmin"})
+		}
+		return nil, txFailure(err, i18n.ErrRoleUpdateFailed, i18n.ErrRoleUpdateFailed)
+	}
+	revokeAccess()
+	recordAudit(ctx, s.Store, AuditRoleChange, models.AuditSuccess, actorID, &user.ID, models.JSONMap{
+		"from": previousRole,
+		"to":   role,
+	})
+	return user, nil
 }
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+)
+
+// writeFailure maps a failed write to the error reported to the client: a
+// unique index violation to 409 duplicateCode, a record changed concurrently
+// to 409 version_conflict, and anything else to an internal error with code.
+func writeFailure(err error, code, duplicateCode string) error {
+	switch {
+	case errors.Is(err, repositories.ErrDuplicate):
+		return apperror.Conflict(duplicateCode)
+	case errors.Is(err, repositories.ErrStale):
+		return apperror.Conflict(i18n.ErrVersionConflict)
+	default:
+		return apperror.Internal(code, err)
+	}
+}
+
+// txFailure returns the error that made a transaction fail when it is
+// already an application error, and otherwise maps it with writeFailure.
+func txFailure(err error, code, duplicateCode string) error {
+	if _, ok := apperror.As(err); ok {
+		return err
+	}
+	return writeFailure(err, code, duplicateCode)
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalURLPrefix is the path under which the application serves the files
+// of a LocalStorage.
+const LocalURLPrefix = "/uploads"
+
+// LocalStorage keeps files in a directory on the local filesystem.
+type LocalStorage struct {
+	Dir       string
+	PublicURL string
+}
+
+func NewLocalStorage(dir, publicURL string) *LocalStorage {
+	return &LocalStorage{Dir: dir, PublicURL: publicURL}
+}
+
+// Put writes the file next to its destination and renames it into place, so
+// readers never see a partial file.
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	key, err := checkKey(key)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, io.LimitReader(body, size)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	key, err := checkKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return joinURL(s.PublicURL, key)
+}
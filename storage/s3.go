@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Config locates a bucket on Amazon S3 or an S3 compatible service such as
+// MinIO, Cloudflare R2 or DigitalOcean Spaces.
+type S3Config struct {
+	// Endpoint is the service URL; empty means AWS in Region.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// ForcePathStyle addresses objects as <endpoint>/<bucket>/<key> instead
+	// of <bucket>.<endpoint host>/<key>, as most self-hosted services need.
+	ForcePathStyle bool
+	// PublicURL, when set, is where objects are served from, e.g. a CDN in
+	// front of the bucket; otherwise the object URL is used.
+	PublicURL string
+}
+
+// S3Storage keeps files in an S3 bucket, signing requests with AWS
+// Signature Version 4.
+type S3Storage struct {
+	Config S3Config
+	Client *http.Client
+}
+
+func NewS3Storage(cfg S3Config) *S3Storage {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	return &S3Storage{
+		Config: cfg,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	key, err := checkKey(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), io.LimitReader(body, size))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	return s.do(req, key)
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := checkKey(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, key)
+}
+
+func (s *S3Storage) URL(key string) string {
+	if s.Config.PublicURL != "" {
+		return joinURL(s.Config.PublicURL, key)
+	}
+	return s.objectURL(key)
+}
+
+func (s *S3Storage) objectURL(key string) string {
+	endpoint := strings.TrimSuffix(s.Config.Endpoint, "/")
+	if s.Config.ForcePathStyle {
+		return endpoint + "/" + uriEncode(s.Config.Bucket, true) + "/" + uriEncode(key, false)
+	}
+	scheme, host, _ := strings.Cut(endpoint, "://")
+	return scheme + "://" + s.Config.Bucket + "." + host + "/" + uriEncode(key, false)
+}
+
+// do signs and sends req. A missing object is not an error for DELETE.
+func (s *S3Storage) do(req *http.Request, key string) error {
+	if s.Config.Bucket == "" {
+		return errors.New("s3 storage: S3_BUCKET is not set")
+	}
+	s.sign(req)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 || (req.Method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s %s: %s: %s", req.Method, key, resp.Status, strings.TrimSpace(string(detail)))
+}
+
+// unsignedPayload leaves the body out of the signature, so it can be
+// streamed; the connection to the service is expected to use TLS.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// sign adds an AWS Signature Version 4 Authorization header to req.
+func (s *S3Storage) sign(req *http.Request) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + unsignedPayload,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := date + "/" + s.Config.Region + "/s3/aws4_request"
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.Config.SecretAccessKey), date)
+	for _, part := range []string{s.Config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.Config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode percent-encodes s as S3 expects: everything but unreserved
+// characters, and slashes too when encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,66 @@
+// Package storage keeps uploaded files. Files are addressed by slash
+// separated keys such as "avatars/<user id>/<name>.png" and served from a
+// public URL; where they live depends on the backend.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+)
+
+// ErrInvalidKey is returned for keys that are empty, absolute or escape the
+// storage root.
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage stores and deletes files and tells where they are served from.
+type Storage interface {
+	// Put stores size bytes read from body under key, replacing any file
+	// already there.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Delete removes the file under key. Deleting a missing file is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of the file under key.
+	URL(key string) string
+}
+
+// New returns the backend selected by STORAGE_DRIVER: S3 for "s3" and the
+// local filesystem otherwise.
+func New(cfg config.Config) Storage {
+	if cfg.StorageDriver == config.StorageS3 {
+		return NewS3Storage(S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			ForcePathStyle:  cfg.S3ForcePathStyle,
+			PublicURL:       cfg.StoragePublicURL,
+		})
+	}
+	publicURL := cfg.StoragePublicURL
+	if publicURL == "" {
+		publicURL = "http://localhost:" + cfg.ServerPort + LocalURLPrefix
+	}
+	return NewLocalStorage(cfg.UploadDir, publicURL)
+}
+
+// checkKey validates key and returns it cleaned.
+func checkKey(key string) (string, error) {
+	cleaned := path.Clean(key)
+	if key == "" || strings.HasPrefix(key, "/") || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return cleaned, nil
+}
+
+// joinURL appends key to base with exactly one slash between them.
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportUserData(t *testing.T) {
+	t.Parallel()
+	app := setupAuthTestApp(t)
+
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Export User",
+		"email":    "export@example.com",
+		"password": "Password123!",
+	})
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &registered))
+
+	resp := performAuthedRequest(t, app, "GET", "/api/user/export", registered.AccessToken, nil)
+	require.Equal(t, 200, resp.Code)
+
+	var export struct {
+		Profile struct {
+			Email string `json:"email"`
+		} `json:"profile"`
+		Sessions    []map[string]any `json:"sessions"`
+		AuditEvents []map[string]any `json:"audit_events"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &export))
+	assert.Equal(t, "export@example.com", export.Profile.Email)
+	assert.Len(t, export.Sessions, 1)
+	assert.NotEmpty(t, export.AuditEvents)
+}
+
+func TestDeleteAccountRevokesAccessAndPurges(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, func(cfg *config.Config) { cfg.DeletionGraceDays = 0 })
+	app := env.App
+
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Leaving User",
+		"email":    "leaving@example.com",
+		"password": "Password123!",
+	})
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &registered))
+
+	resp := performAuthedRequest(t, app, "POST", "/api/user/delete", registered.AccessToken, map[string]string{
+		"password": "wrong-password",
+	})
+	require.Equal(t, 401, resp.Code)
+
+	resp = performAuthedRequest(t, app, "POST", "/api/user/delete", registered.AccessToken, map[string]string{
+		"password": "Password123!",
+	})
+	require.Equal(t, 200, resp.Code)
+
+	resp = performAuthedRequest(t, app, "GET", "/api/user/profile", registered.AccessToken, nil)
+	assert.Equal(t, 401, resp.Code)
+
+	resp = performJSONRequest(t, app, "POST", "/api/auth/refresh", map[string]string{
+		"refresh_token": registered.RefreshToken,
+	})
+	assert.Equal(t, 401, resp.Code)
+
+	resp = performJSONRequest(t, app, "POST", "/api/auth/login", map[string]string{
+		"email":    "leaving@example.com",
+		"password": "Password123!",
+	})
+	assert.Equal(t, 401, resp.Code)
+
+	var count int64
+	env.DB().Model(&models.PasswordHistory{}).Where("user_id = ?", registered.User.ID).Count(&count)
+	require.Equal(t, int64(1), count)
+
+	_, err := env.Container.Accounts.PurgeDeletedAccounts(context.Background())
+	require.NoError(t, err)
+
+	env.DB().Unscoped().Model(&models.User{}).Where("email = ?", "leaving@example.com").Count(&count)
+	assert.Zero(t, count)
+	env.DB().Model(&models.PasswordHistory{}).Where("user_id = ?", registered.User.ID).Count(&count)
+	assert.Zero(t, count, "old password hashes are purged with the account")
+}
+
+func TestDeleteAccountWithoutPasswordConfirmsByEmail(t *testing.T) {
+	t.Parallel()
+	rec := &recordingMailer{}
+	env := newTestApp(t, nil, container.WithMailer(rec))
+	ctx := context.Background()
+
+	// An account created through Google sign-in has no password.
+	user := models.User{
+		ID:       utils.GenerateUUID(),
+		Name:     "Google Only",
+		Email:    "google-only@example.com",
+		Username: "google_only",
+		Role:     models.RoleUser,
+	}
+	require.NoError(t, env.Container.Store.Users().Create(ctx, &user))
+	token, err := env.Container.Tokens.GenerateJWT(user.ID.String())
+	require.NoError(t, err)
+
+	resp := performAuthedRequest(t, env.App, "POST", "/api/user/delete", token, map[string]string{"password": ""})
+	require.Equal(t, 202, resp.Code)
+	sent := rec.sentTo("google-only@example.com")
+	require.Len(t, sent, 1)
+	match := tokenPattern.FindStringSubmatch(sent[0].Body)
+	require.Len(t, match, 2)
+
+	resp = performAuthedRequest(t, env.App, "POST", "/api/user/delete", token, map[string]string{"token": "not-the-token"})
+	require.Equal(t, 401, resp.Code)
+
+	resp = performAuthedRequest(t, env.App, "POST", "/api/user/delete", token, map[string]string{"token": match[1]})
+	require.Equal(t, 200, resp.Code)
+	assert.Equal(t, 401, performAuthedRequest(t, env.App, "GET", "/api/user/profile", token, nil).Code)
+
+	var deleted models.User
+	require.NoError(t, env.DB().Unscoped().First(&deleted, "id = ?", user.ID).Error)
+	assert.True(t, deleted.DeletedAt.Valid)
+	assert.Empty(t, deleted.DeletionToken)
+}
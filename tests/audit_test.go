@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginAttemptsAreAudited(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	app := env.App
+
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Audited User",
+		"email":    "audited@example.com",
+		"password": "Password123!",
+	})
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &registered))
+
+	performJSONRequest(t, app, "POST", "/api/auth/login", map[string]string{
+		"email":    "audited@example.com",
+		"password": "wrong-password",
+	})
+	performJSONRequest(t, app, "POST", "/api/auth/login", map[string]string{
+		"email":    "audited@example.com",
+		"password": "Password123!",
+	})
+
+	admin := models.User{
+		ID:       utils.GenerateUUID(),
+		Name:     "Audit Admin",
+		Email:    "audit-admin@example.com",
+		Username: utils.GenerateUsername("Audit Admin"),
+		Role:     models.RoleSuperAdmin,
+	}
+	require.NoError(t, env.DB().Create(&admin).Error)
+	adminToken, err := env.Container.Tokens.GenerateJWTRole(admin.ID.String(), admin.Role)
+	require.NoError(t, err)
+
+	resp := performAuthedRequest(t, app, "GET", "/api/admin/audit-events?action=auth.login&target_id="+registered.User.ID, adminToken, nil)
+	require.Equal(t, 200, resp.Code)
+
+	var payload struct {
+		Data []models.AuditEvent `json:"data"`
+		Meta struct {
+			Total int64 `json:"total"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+	require.Len(t, payload.Data, 2)
+	assert.Equal(t, int64(2), payload.Meta.Total)
+
+	outcomes := []string{payload.Data[0].Outcome, payload.Data[1].Outcome}
+	assert.ElementsMatch(t, []string{models.AuditSuccess, models.AuditFailure}, outcomes)
+
+	resp = performAuthedRequest(t, app, "GET", "/api/admin/audit-events?action=auth.login&outcome=failure&target_id="+registered.User.ID, adminToken, nil)
+	require.Equal(t, 200, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+	require.Len(t, payload.Data, 1)
+	assert.Equal(t, "audited@example.com", payload.Data[0].Metadata["email"])
+}
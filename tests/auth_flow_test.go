@@ -3,15 +3,21 @@ package tests
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
 	"github.com/ElvinEga/gofiber_starter/database"
 	"github.com/ElvinEga/gofiber_starter/routes"
+	"github.com/ElvinEga/gofiber_starter/utils"
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 type authPayload struct {
@@ -27,24 +33,57 @@ type authPayload struct {
 }
 
 type errorPayload struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Code   string `json:"code"`
 }
 
-func setupAuthTestApp(t *testing.T) *fiber.App {
+// testApp is an application instance with its own container and private
+// in-memory database, so tests using one can run in parallel.
+type testApp struct {
+	*fiber.App
+	Container *container.Container
+}
+
+// DB is the test app's database.
+func (a *testApp) DB() *gorm.DB {
+	return a.Container.DB
+}
+
+// newTestApp builds an isolated app. configure, when not nil, adjusts the
+// configuration before the container is built.
+func newTestApp(t *testing.T, configure func(*config.Config), opts ...container.Option) *testApp {
 	t.Helper()
 
-	t.Setenv("DATABASE_URL", "")
-	t.Setenv("DB_PATH", "file::memory:?cache=shared")
-	t.Setenv("JWT_SECRET", "test-secret")
+	cfg := config.Load()
+	cfg.DBDriver = config.DriverSQLite
+	cfg.DatabaseURL = ""
+	cfg.DBPath = fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString())
+	cfg.JWTSecret = "test-secret"
+	if configure != nil {
+		configure(&cfg)
+	}
 
-	config.InitConfig()
-	database.ConnectDB()
-	database.MigrateDB()
+	db, err := database.ConnectDB(cfg)
+	require.NoError(t, err)
+	require.NoError(t, database.MigrateDB(db))
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
 
-	app := fiber.New()
-	routes.SetupRoutes(app)
-	return app
+	ctr, err := container.New(cfg, db, opts...)
+	require.NoError(t, err)
+	app := fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler})
+	routes.SetupRoutes(app, ctr)
+	return &testApp{App: app, Container: ctr}
+}
+
+func setupAuthTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+	return newTestApp(t, nil).App
 }
 
 func performJSONRequest(t *testing.T, app *fiber.App, method, path string, body any) *httptest.ResponseRecorder {
@@ -66,6 +105,7 @@ func performJSONRequest(t *testing.T, app *fiber.App, method, path string, body
 }
 
 func TestRegisterReturnsTokenPair(t *testing.T) {
+	t.Parallel()
 	app := setupAuthTestApp(t)
 
 	resp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
@@ -85,6 +125,7 @@ func TestRegisterReturnsTokenPair(t *testing.T) {
 }
 
 func TestRegisterRejectsDuplicateEmail(t *testing.T) {
+	t.Parallel()
 	app := setupAuthTestApp(t)
 
 	performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
@@ -103,10 +144,11 @@ func TestRegisterRejectsDuplicateEmail(t *testing.T) {
 
 	var payload errorPayload
 	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
-	assert.Equal(t, "error", payload.Status)
+	assert.Equal(t, resp.Code, payload.Status)
 }
 
 func TestLoginReturnsTokenPair(t *testing.T) {
+	t.Parallel()
 	app := setupAuthTestApp(t)
 
 	performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
@@ -130,6 +172,7 @@ func TestLoginReturnsTokenPair(t *testing.T) {
 }
 
 func TestLoginRejectsInvalidCredentials(t *testing.T) {
+	t.Parallel()
 	app := setupAuthTestApp(t)
 
 	resp := performJSONRequest(t, app, "POST", "/api/auth/login", map[string]string{
@@ -141,10 +184,11 @@ func TestLoginRejectsInvalidCredentials(t *testing.T) {
 
 	var payload errorPayload
 	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
-	assert.Equal(t, "error", payload.Status)
+	assert.Equal(t, resp.Code, payload.Status)
 }
 
 func TestRefreshReturnsNewTokenPair(t *testing.T) {
+	t.Parallel()
 	app := setupAuthTestApp(t)
 
 	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
@@ -171,6 +215,7 @@ func TestRefreshReturnsNewTokenPair(t *testing.T) {
 }
 
 func TestRefreshRejectsInvalidToken(t *testing.T) {
+	t.Parallel()
 	app := setupAuthTestApp(t)
 
 	resp := performJSONRequest(t, app, "POST", "/api/auth/refresh", map[string]string{
@@ -181,5 +226,30 @@ func TestRefreshRejectsInvalidToken(t *testing.T) {
 
 	var payload errorPayload
 	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
-	assert.Equal(t, "error", payload.Status)
+	assert.Equal(t, resp.Code, payload.Status)
+}
+
+func TestAccessTokenLifetimeFollowsJWTExpiration(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, func(cfg *config.Config) {
+		cfg.JWTExpiration = 2
+	})
+
+	resp := performJSONRequest(t, env.App, "POST", "/api/auth/register", map[string]string{
+		"name":     "Short Lived",
+		"email":    "short-lived@example.com",
+		"password": "Password123!",
+	})
+	require.Equal(t, 201, resp.Code)
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &registered))
+
+	claims, err := env.Container.Tokens.ParseJWTClaims(registered.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, claims.ExpiresAt.Sub(claims.IssuedAt.Time))
+
+	cfg := config.Load()
+	cfg.JWTExpiration = 0
+	_, err = container.New(cfg, nil)
+	assert.ErrorContains(t, err, "JWT_EXPIRATION")
 }
@@ -3,17 +3,22 @@ package tests
 import (
 	"testing"
 
+	"github.com/ElvinEga/gofiber_starter/config"
 	"github.com/ElvinEga/gofiber_starter/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPasswordHashing(t *testing.T) {
+	hasher, err := utils.NewPasswordHasher(config.Load())
+	require.NoError(t, err)
 	password := "test123"
-	hashed := utils.HashPassword(password)
+	hashed, err := hasher.Hash(password)
 
+	require.NoError(t, err)
 	assert.NotEmpty(t, hashed)
-	assert.True(t, utils.CheckPasswordHash(password, hashed))
-	assert.False(t, utils.CheckPasswordHash("wrongpassword", hashed))
+	assert.True(t, utils.CheckPasswordHash(hasher, password, hashed))
+	assert.False(t, utils.CheckPasswordHash(hasher, "wrongpassword", hashed))
 }
 
 func TestGenerateUsername(t *testing.T) {
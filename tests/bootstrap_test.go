@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestBootstrapAdminMustChangePassword(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, func(cfg *config.Config) {
+		cfg.BootstrapAdminEmail = "root@example.com"
+		cfg.BootstrapAdminPassword = "Bootstrap123!"
+	})
+
+	result, err := env.Container.Bootstrap.Bootstrap(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, result.Created)
+	assert.Empty(t, result.SetupToken)
+	assert.True(t, result.Created.MustChangePassword)
+
+	// Bootstrapping again leaves the existing superadmin alone.
+	again, err := env.Container.Bootstrap.Bootstrap(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, again.Created)
+
+	loginResp := performJSONRequest(t, env.App, "POST", "/api/auth/login", map[string]string{
+		"email":    "root@example.com",
+		"password": "Bootstrap123!",
+	})
+	require.Equal(t, 200, loginResp.Code)
+	var login struct {
+		authPayload
+		User struct {
+			MustChangePassword bool `json:"must_change_password"`
+		} `json:"user"`
+	}
+	require.NoError(t, json.Unmarshal(loginResp.Body.Bytes(), &login))
+	assert.True(t, login.User.MustChangePassword)
+
+	blocked := performAuthedRequest(t, env.App, "GET", "/api/admin/audit-events", login.AccessToken, nil)
+	require.Equal(t, 403, blocked.Code)
+	var problem errorPayload
+	require.NoError(t, json.Unmarshal(blocked.Body.Bytes(), &problem))
+	assert.Equal(t, "password_change_required", problem.Code)
+	assert.Equal(t, 200, performAuthedRequest(t, env.App, "GET", "/api/user/profile", login.AccessToken, nil).Code)
+
+	changeResp := performAuthedRequest(t, env.App, "PUT", "/api/user/password", login.AccessToken, map[string]string{
+		"current_password": "Bootstrap123!",
+		"new_password":     "Chosen456!",
+		"refresh_token":    login.RefreshToken,
+	})
+	require.Equal(t, 200, changeResp.Code)
+	var changed struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.Unmarshal(changeResp.Body.Bytes(), &changed))
+	assert.Equal(t, 200, performAuthedRequest(t, env.App, "GET", "/api/admin/audit-events", changed.AccessToken, nil).Code)
+}
+
+// fakeGoogle answers the OAuth token exchange and the userinfo request with
+// the given profile.
+type fakeGoogle struct {
+	info utils.GoogleUserInfo
+}
+
+func (g fakeGoogle) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := json.Marshal(map[string]any{"access_token": "google-token", "token_type": "Bearer"})
+	if req.URL.Host == "www.googleapis.com" {
+		body, _ = json.Marshal(g.info)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestGoogleSignInMustChangePassword(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, func(cfg *config.Config) {
+		cfg.BootstrapAdminEmail = "root@example.com"
+		cfg.BootstrapAdminPassword = "Bootstrap123!"
+	})
+	_, err := env.Container.Bootstrap.Bootstrap(context.Background())
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: fakeGoogle{utils.GoogleUserInfo{ID: "google-root", Email: "root@example.com", Name: "Root"}}}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+	result, err := env.Container.Auth.GoogleSignIn(ctx, "code")
+	require.NoError(t, err)
+	assert.False(t, result.Created)
+
+	claims, err := env.Container.Tokens.ParseJWTClaims(result.Token)
+	require.NoError(t, err)
+	assert.True(t, claims.PasswordChange)
+	assert.Equal(t, 403, performAuthedRequest(t, env.App, "GET", "/api/admin/audit-events", result.Token, nil).Code)
+}
+
+func TestSetupTokenCreatesFirstSuperadmin(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+
+	result, err := env.Container.Bootstrap.Bootstrap(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, result.SetupToken)
+
+	body := map[string]string{
+		"token":    "wrong",
+		"name":     "First Admin",
+		"email":    "first@example.com",
+		"password": "Password123!",
+	}
+	assert.Equal(t, 403, performJSONRequest(t, env.App, "POST", "/api/setup", body).Code)
+
+	body["token"] = result.SetupToken
+	require.Equal(t, 201, performJSONRequest(t, env.App, "POST", "/api/setup", body).Code)
+	assert.Equal(t, 404, performJSONRequest(t, env.App, "POST", "/api/setup", body).Code)
+
+	admin, err := env.Container.Users.FindByEmail(context.Background(), "first@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleSuperAdmin, admin.Role)
+	assert.False(t, admin.MustChangePassword)
+}
+
+func TestBootstrapRefusesDefaultsInProduction(t *testing.T) {
+	t.Parallel()
+
+	env := newTestApp(t, func(cfg *config.Config) {
+		cfg.AppEnv = config.EnvProduction
+		cfg.JWTSecret = "secret"
+	})
+	_, err := env.Container.Bootstrap.Bootstrap(context.Background())
+	assert.ErrorIs(t, err, services.ErrDefaultJWTSecret)
+
+	env = newTestApp(t, func(cfg *config.Config) {
+		cfg.AppEnv = config.EnvProduction
+	})
+	hash, err := env.Container.Hasher.Hash("admin1234")
+	require.NoError(t, err)
+	require.NoError(t, env.Container.Store.Users().Create(context.Background(), &models.User{
+		ID:       utils.GenerateUUID(),
+		Name:     "Super Admin",
+		Email:    "admin@example.com",
+		Username: "superadmin",
+		Password: hash,
+		Role:     models.RoleSuperAdmin,
+	}))
+	_, err = env.Container.Bootstrap.Bootstrap(context.Background())
+	assert.ErrorIs(t, err, services.ErrDefaultCredentials)
+}
+
+func TestBootstrapRefusesUnknownRegistrationMode(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, func(cfg *config.Config) {
+		cfg.RegistrationMode = "invite-only"
+	})
+	_, err := env.Container.Bootstrap.Bootstrap(context.Background())
+	assert.ErrorIs(t, err, services.ErrUnknownRegistrationMode)
+
+	for _, mode := range []string{config.RegistrationOpen, config.RegistrationInviteOnly, config.RegistrationClosed} {
+		env := newTestApp(t, func(cfg *config.Config) {
+			cfg.RegistrationMode = mode
+		})
+		_, err := env.Container.Bootstrap.Bootstrap(context.Background())
+		assert.NoError(t, err, mode)
+	}
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectDBAppliesPoolAndPragmas(t *testing.T) {
+	t.Parallel()
+	cfg := config.Config{
+		DBPath:                filepath.Join(t.TempDir(), "tuned.db"),
+		DBMaxOpenConns:        7,
+		DBMaxIdleConns:        2,
+		DBSQLiteWAL:           true,
+		DBSQLiteBusyTimeoutMS: 1234,
+	}
+	db, err := database.ConnectDB(cfg)
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	assert.Equal(t, 7, sqlDB.Stats().MaxOpenConnections)
+
+	var journalMode string
+	require.NoError(t, db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error)
+	assert.Equal(t, "wal", journalMode)
+	var busyTimeout int
+	require.NoError(t, db.Raw("PRAGMA busy_timeout").Scan(&busyTimeout).Error)
+	assert.Equal(t, 1234, busyTimeout)
+}
+
+func TestConnectDBReturnsErrorAfterRetries(t *testing.T) {
+	t.Parallel()
+	_, err := database.ConnectDB(config.Config{
+		DBPath:             filepath.Join(t.TempDir(), "missing", "dir", "x.db"),
+		DBConnectRetries:   2,
+		DBConnectBackoffMS: 1,
+	})
+	assert.ErrorContains(t, err, "cannot connect to database")
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialectConfigs returns a configuration per database engine to test
+// against: SQLite always, and each server engine whose TEST_<DRIVER>_URL
+// points at an empty database.
+func dialectConfigs() map[string]config.Config {
+	configs := map[string]config.Config{
+		config.DriverSQLite: {DBPath: fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString())},
+	}
+	for driver, env := range map[string]string{
+		config.DriverPostgres:  "TEST_POSTGRES_URL",
+		config.DriverMySQL:     "TEST_MYSQL_URL",
+		config.DriverSQLServer: "TEST_SQLSERVER_URL",
+	} {
+		if dsn := os.Getenv(env); dsn != "" {
+			configs[driver] = config.Config{DBDriver: driver, DatabaseURL: dsn, DBMaxOpenConns: 5, DBMaxIdleConns: 1}
+		}
+	}
+	return configs
+}
+
+func TestAuthFlowOnEveryDialect(t *testing.T) {
+	t.Parallel()
+	for driver, cfg := range dialectConfigs() {
+		t.Run(driver, func(t *testing.T) {
+			t.Parallel()
+			db, err := database.ConnectDB(cfg)
+			require.NoError(t, err)
+			require.NoError(t, database.MigrateDB(db))
+			t.Cleanup(func() {
+				states, _ := database.MigrationStatus(db)
+				assert.NoError(t, database.RollbackDB(db, len(states)))
+				if sqlDB, err := db.DB(); err == nil {
+					sqlDB.Close()
+				}
+			})
+
+			cfg.JWTSecret = "test-secret"
+			cfg.JWTExpiration = 72
+			cfg.PasswordMinLength = 8
+			cfg.PasswordMaxBytes = 72
+			cfg.PasswordHistorySize = 2
+			cfg.PasswordHashAlgorithm = utils.HashBcrypt
+			cfg.BcryptCost = 4
+			cfg.Argon2MemoryKiB, cfg.Argon2Iterations, cfg.Argon2Parallelism = 19456, 2, 1
+			ctr, err := container.New(cfg, db)
+			require.NoError(t, err)
+			ctx := context.Background()
+			email := fmt.Sprintf("%s-%s@example.com", driver, uuid.NewString()[:8])
+
+			registered, err := ctr.Auth.Register(ctx, services.RegisterInput{Name: "Dialect User", Email: email, Password: "Password123!"})
+			require.NoError(t, err)
+			_, err = ctr.Auth.Register(ctx, services.RegisterInput{Name: "Dialect User", Email: email, Password: "Password123!"})
+			require.Error(t, err, "duplicate email")
+
+			refreshed, err := ctr.Auth.Refresh(ctx, registered.RefreshToken)
+			require.NoError(t, err)
+			assert.Equal(t, registered.User.ID, refreshed.User.ID)
+
+			current := "Password123!"
+			for i := range 3 {
+				next := fmt.Sprintf("Changed%dPassword!", i)
+				_, err := ctr.Users.ChangePassword(ctx, registered.User.ID, services.ChangePasswordInput{CurrentPassword: current, NewPassword: next})
+				require.NoError(t, err)
+				current = next
+			}
+			loggedIn, err := ctr.Auth.Login(ctx, services.LoginInput{Email: email, Password: current})
+			require.NoError(t, err)
+			assert.Equal(t, registered.User.ID, loggedIn.User.ID)
+
+			demoteConcurrently(t, ctr)
+		})
+	}
+}
+
+func TestConnectDBRejectsUnknownOrIncompleteDriver(t *testing.T) {
+	t.Parallel()
+	_, err := database.ConnectDB(config.Config{DBDriver: "oracle", DatabaseURL: "oracle://db"})
+	assert.ErrorContains(t, err, "unknown DB_DRIVER")
+	_, err = database.ConnectDB(config.Config{DBDriver: config.DriverMySQL})
+	assert.ErrorContains(t, err, "DATABASE_URL is required")
+}
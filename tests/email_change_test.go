@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/mailer"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *recordingMailer) sentTo(to string) []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []mailer.Message
+	for _, msg := range m.messages {
+		if msg.To == to {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+var tokenPattern = regexp.MustCompile(`token=([0-9a-f]+)`)
+
+func TestEmailChangeRequiresConfirmation(t *testing.T) {
+	t.Parallel()
+	rec := &recordingMailer{}
+	app := newTestApp(t, nil, container.WithMailer(rec)).App
+
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Moving User",
+		"email":    "old-address@example.com",
+		"password": "Password123!",
+	})
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &registered))
+
+	resp := performAuthedRequest(t, app, "POST", "/api/user/email", registered.AccessToken, map[string]string{
+		"new_email": "new-address@example.com",
+		"password":  "Password123!",
+	})
+	require.Equal(t, 200, resp.Code)
+
+	require.Len(t, rec.sentTo("old-address@example.com"), 1)
+	confirmations := rec.sentTo("new-address@example.com")
+	require.Len(t, confirmations, 1)
+
+	// Nothing changes until the new address is confirmed.
+	profileResp := performAuthedRequest(t, app, "GET", "/api/user/profile", registered.AccessToken, nil)
+	var profile struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal(profileResp.Body.Bytes(), &profile))
+	assert.Equal(t, "old-address@example.com", profile.Email)
+
+	match := tokenPattern.FindStringSubmatch(confirmations[0].Body)
+	require.Len(t, match, 2)
+
+	resp = performJSONRequest(t, app, "GET", "/api/auth/confirm-email?token="+match[1], nil)
+	require.Equal(t, 200, resp.Code)
+
+	profileResp = performAuthedRequest(t, app, "GET", "/api/user/profile", registered.AccessToken, nil)
+	require.NoError(t, json.Unmarshal(profileResp.Body.Bytes(), &profile))
+	assert.Equal(t, "new-address@example.com", profile.Email)
+
+	// Tokens are single use.
+	resp = performJSONRequest(t, app, "GET", "/api/auth/confirm-email?token="+match[1], nil)
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestEmailChangeRejectsTakenAddress(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, nil, container.WithMailer(&recordingMailer{})).App
+
+	performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Existing User",
+		"email":    "taken-address@example.com",
+		"password": "Password123!",
+	})
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Grabbing User",
+		"email":    "grabber@example.com",
+		"password": "Password123!",
+	})
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &registered))
+
+	resp := performAuthedRequest(t, app, "POST", "/api/user/email", registered.AccessToken, map[string]string{
+		"new_email": "taken-address@example.com",
+		"password":  "Password123!",
+	})
+	assert.Equal(t, 409, resp.Code)
+}
+
+func TestEmailChangeWithoutPasswordAuthorizesByEmail(t *testing.T) {
+	t.Parallel()
+	rec := &recordingMailer{}
+	env := newTestApp(t, nil, container.WithMailer(rec))
+	ctx := context.Background()
+
+	// An account created through Google sign-in has no password.
+	user := models.User{
+		ID:       utils.GenerateUUID(),
+		Name:     "Google Mover",
+		Email:    "google-mover@example.com",
+		Username: "google_mover",
+		Role:     models.RoleUser,
+	}
+	require.NoError(t, env.Container.Store.Users().Create(ctx, &user))
+	token, err := env.Container.Tokens.GenerateJWT(user.ID.String())
+	require.NoError(t, err)
+
+	resp := performAuthedRequest(t, env.App, "POST", "/api/user/email", token, map[string]string{
+		"new_email": "google-moved@example.com",
+	})
+	require.Equal(t, 202, resp.Code)
+	assert.Empty(t, rec.sentTo("google-moved@example.com"))
+	authorizations := rec.sentTo("google-mover@example.com")
+	require.Len(t, authorizations, 1)
+	match := tokenPattern.FindStringSubmatch(authorizations[0].Body)
+	require.Len(t, match, 2)
+
+	resp = performAuthedRequest(t, env.App, "POST", "/api/user/email", token, map[string]string{
+		"new_email": "google-moved@example.com",
+		"token":     "not-the-token",
+	})
+	require.Equal(t, 401, resp.Code)
+	// The token only authorizes the address it was sent for.
+	resp = performAuthedRequest(t, env.App, "POST", "/api/user/email", token, map[string]string{
+		"new_email": "somewhere-else@example.com",
+		"token":     match[1],
+	})
+	require.Equal(t, 401, resp.Code)
+
+	resp = performAuthedRequest(t, env.App, "POST", "/api/user/email", token, map[string]string{
+		"new_email": "google-moved@example.com",
+		"token":     match[1],
+	})
+	require.Equal(t, 200, resp.Code)
+	confirmations := rec.sentTo("google-moved@example.com")
+	require.Len(t, confirmations, 1)
+	match = tokenPattern.FindStringSubmatch(confirmations[0].Body)
+	require.Len(t, match, 2)
+
+	resp = performJSONRequest(t, env.App, "GET", "/api/auth/confirm-email?token="+match[1], nil)
+	require.Equal(t, 200, resp.Code)
+
+	var moved models.User
+	require.NoError(t, env.DB().First(&moved, "id = ?", user.ID).Error)
+	assert.Equal(t, "google-moved@example.com", moved.Email)
+	assert.Empty(t, moved.EmailChangeAuthToken)
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/mailer"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	cases := map[string]string{
+		"":                        "en",
+		"es":                      "es",
+		"es-MX,es;q=0.9":          "es",
+		"de-DE,fr;q=0.8,en;q=0.5": "fr",
+		"en;q=0.4, fr-CA;q=0.9":   "fr",
+		"de, *;q=0.1":             "en",
+		"fr;q=0, es;q=0.2":        "es",
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, i18n.Negotiate(header), header)
+	}
+}
+
+func TestErrorsAreLocalized(t *testing.T) {
+	t.Parallel()
+	app := setupAuthTestApp(t)
+
+	payload, err := json.Marshal(map[string]string{"name": "", "email": "not-an-email", "password": "Password123!"})
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/api/auth/register", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "es-ES,es;q=0.9,en;q=0.5")
+
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 0, FailOnTimeout: false})
+	require.NoError(t, err)
+	require.Equal(t, 422, resp.StatusCode)
+	assert.Equal(t, "es", resp.Header.Get("Content-Language"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var result struct {
+		Code   string                  `json:"code"`
+		Title  string                  `json:"title"`
+		Errors []utils.ValidationError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(body, &result))
+	assert.Equal(t, i18n.ErrValidationFailed, result.Code)
+	assert.Equal(t, "La validación ha fallado", result.Title)
+
+	messages := map[string]string{}
+	for _, e := range result.Errors {
+		messages[e.Field] = e.Message
+	}
+	assert.Equal(t, "Este campo es obligatorio", messages["name"])
+	assert.Equal(t, "Debe ser una dirección de correo válida", messages["email"])
+}
+
+func TestEmailTemplatesFallBackToDefaultLocale(t *testing.T) {
+	subject, _, err := mailer.Render("fr", mailer.TemplatePasswordReset, map[string]string{"Name": "A", "Link": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "Réinitialisez votre mot de passe", subject)
+
+	subject, _, err = mailer.Render("de", mailer.TemplatePasswordReset, map[string]string{"Name": "A", "Link": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "Reset your password", subject)
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performAuthedRequest(t *testing.T, app *fiber.App, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 0, FailOnTimeout: false})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	recorder.Code = resp.StatusCode
+	_, _ = recorder.Body.ReadFrom(resp.Body)
+	return recorder
+}
+
+func TestSuperadminCanImpersonateUser(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	app := env.App
+
+	admin := models.User{
+		ID:       utils.GenerateUUID(),
+		Name:     "Impersonating Admin",
+		Email:    "impersonator@example.com",
+		Username: utils.GenerateUsername("Impersonating Admin"),
+		Role:     "superadmin",
+	}
+	require.NoError(t, env.DB().Create(&admin).Error)
+	adminToken, err := env.Container.Tokens.GenerateJWTRole(admin.ID.String(), admin.Role)
+	require.NoError(t, err)
+
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Target User",
+		"email":    "target@example.com",
+		"password": "Password123!",
+	})
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &registered))
+
+	resp := performAuthedRequest(t, app, "POST", "/api/admin/users/"+registered.User.ID+"/impersonate", adminToken, map[string]string{
+		"reason": "support ticket",
+	})
+	require.Equal(t, 200, resp.Code)
+
+	var issued struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &issued))
+	require.NotEmpty(t, issued.AccessToken)
+
+	var count int64
+	env.DB().Model(&models.ImpersonationLog{}).Where("impersonator_id = ? AND target_user_id = ?", admin.ID, registered.User.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	profileResp := performAuthedRequest(t, app, "GET", "/api/user/profile", issued.AccessToken, nil)
+	require.Equal(t, 200, profileResp.Code)
+	var profile struct {
+		Email         string `json:"email"`
+		Impersonation struct {
+			ImpersonatorID string `json:"impersonator_id"`
+		} `json:"impersonation"`
+	}
+	require.NoError(t, json.Unmarshal(profileResp.Body.Bytes(), &profile))
+	assert.Equal(t, "target@example.com", profile.Email)
+	assert.Equal(t, admin.ID.String(), profile.Impersonation.ImpersonatorID)
+
+	passwordResp := performAuthedRequest(t, app, "PUT", "/api/user/password", issued.AccessToken, map[string]string{
+		"current_password": "Password123!",
+		"new_password":     "Another123!",
+	})
+	assert.Equal(t, 403, passwordResp.Code)
+}
+
+func TestImpersonationRequiresSuperadmin(t *testing.T) {
+	t.Parallel()
+	app := setupAuthTestApp(t)
+
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Regular User",
+		"email":    "regular-impersonator@example.com",
+		"password": "Password123!",
+	})
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &registered))
+
+	resp := performAuthedRequest(t, app, "POST", "/api/admin/users/"+registered.User.ID+"/impersonate", registered.AccessToken, nil)
+	assert.Equal(t, 403, resp.Code)
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestMigrationsApplyAndRollBack(t *testing.T) {
+	t.Parallel()
+	db, err := database.ConnectDB(config.Config{
+		DBPath: fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString()),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	states, err := database.MigrationStatus(db)
+	require.NoError(t, err)
+	require.NotEmpty(t, states)
+	for _, s := range states {
+		assert.Nil(t, s.AppliedAt, "migration %d", s.Version)
+	}
+
+	require.NoError(t, database.MigrateDB(db))
+	require.NoError(t, database.MigrateDB(db), "migrating twice is a no-op")
+	assert.True(t, db.Migrator().HasTable("users"))
+	assert.True(t, db.Migrator().HasIndex("user_identities", "idx_identity_provider_subject"))
+
+	states, err = database.MigrationStatus(db)
+	require.NoError(t, err)
+	for _, s := range states {
+		assert.NotNil(t, s.AppliedAt, "migration %d", s.Version)
+	}
+
+	require.NoError(t, database.RollbackDB(db, len(states)))
+	assert.False(t, db.Migrator().HasTable("users"))
+	states, err = database.MigrationStatus(db)
+	require.NoError(t, err)
+	for _, s := range states {
+		assert.Nil(t, s.AppliedAt, "migration %d", s.Version)
+	}
+}
+
+// baselineUser and baselineRefreshToken are the models the schema was
+// created from with AutoMigrate before versioned migrations existed.
+type baselineUser struct {
+	ID                uuid.UUID `gorm:"type:text;primaryKey"`
+	Name              string
+	Username          string `gorm:"uniqueIndex"`
+	Email             string `gorm:"uniqueIndex"`
+	Password          string
+	Role              string
+	IsVerified        bool
+	EmailVerifiedAt   time.Time
+	VerificationToken string
+	ResetToken        string
+	ResetExpiresAt    time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineRefreshToken struct {
+	ID        uuid.UUID `gorm:"type:text;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:text;index"`
+	Token     string    `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func (baselineRefreshToken) TableName() string { return "refresh_tokens" }
+
+func TestMigrationsUpgradeAutoMigratedDatabase(t *testing.T) {
+	t.Parallel()
+	cfg := config.Load()
+	cfg.DBDriver = config.DriverSQLite
+	cfg.DBPath = fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString())
+	cfg.JWTSecret = "test-secret"
+	db, err := database.ConnectDB(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	require.NoError(t, db.AutoMigrate(&baselineUser{}, &baselineRefreshToken{}))
+	hasher, err := utils.NewPasswordHasher(cfg)
+	require.NoError(t, err)
+	hash, err := hasher.Hash("Password123!")
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&baselineUser{
+		ID:         uuid.New(),
+		Name:       "Legacy User",
+		Username:   "legacy",
+		Email:      "legacy@example.com",
+		Password:   hash,
+		Role:       models.RoleUser,
+		IsVerified: true,
+	}).Error)
+
+	require.NoError(t, database.MigrateDB(db))
+	states, err := database.MigrationStatus(db)
+	require.NoError(t, err)
+	for _, s := range states {
+		assert.NotNil(t, s.AppliedAt, "migration %d", s.Version)
+	}
+	assert.True(t, db.Migrator().HasColumn("users", "email_change_token"))
+	assert.True(t, db.Migrator().HasIndex("users", "idx_users_email_change_token"))
+
+	ctr, err := container.New(cfg, db)
+	require.NoError(t, err)
+	login, err := ctr.Auth.Login(context.Background(), services.LoginInput{Email: "legacy@example.com", Password: "Password123!"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), login.User.Version)
+}
+
+func TestMigrationsExistForEveryDialect(t *testing.T) {
+	t.Parallel()
+	sqlite, err := database.LoadMigrations("sqlite")
+	require.NoError(t, err)
+
+	for _, dialect := range []string{"postgres", "mysql", "sqlserver"} {
+		migrations, err := database.LoadMigrations(dialect)
+		require.NoError(t, err, dialect)
+		require.Len(t, migrations, len(sqlite), dialect)
+		for i := range sqlite {
+			assert.Equal(t, sqlite[i].Version, migrations[i].Version, dialect)
+			assert.Equal(t, sqlite[i].Name, migrations[i].Name, dialect)
+			assert.NotEmpty(t, sqlite[i].Down)
+			assert.NotEmpty(t, migrations[i].Down, dialect)
+		}
+	}
+}
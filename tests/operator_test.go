@@ -0,0 +1,244 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/blacklist"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperatorManagesUsers(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil, container.WithMailer(&recordingMailer{}))
+	users := env.Container.Users
+	ctx := context.Background()
+
+	user, err := users.CreateUser(ctx, services.CreateUserInput{
+		Name:     "Operator Made",
+		Email:    "made@example.com",
+		Password: "Password123!",
+		Role:     models.RoleAdmin,
+	})
+	require.NoError(t, err)
+	assert.True(t, user.IsVerified)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+
+	_, err = users.CreateUser(ctx, services.CreateUserInput{Name: "Again", Email: "made@example.com", Password: "Password123!"})
+	appErr, ok := apperror.As(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, appErr.Status)
+
+	_, err = users.UpdateRole(ctx, user.ID, "root")
+	appErr, ok = apperror.As(err)
+	require.True(t, ok)
+	assert.Equal(t, i18n.ErrInvalidRole, appErr.Code)
+
+	login := performJSONRequest(t, env.App, "POST", "/api/auth/login", map[string]string{
+		"email":    "made@example.com",
+		"password": "Password123!",
+	})
+	require.Equal(t, 200, login.Code)
+
+	found, err := users.FindByEmail(ctx, "made@example.com")
+	require.NoError(t, err)
+	require.NoError(t, users.SetPassword(ctx, found.ID, "Replaced456!"))
+
+	sessions, err := env.Container.Store.RefreshTokens().ListForUser(ctx, found.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	assert.Equal(t, 401, performJSONRequest(t, env.App, "POST", "/api/auth/login", map[string]string{
+		"email":    "made@example.com",
+		"password": "Password123!",
+	}).Code)
+	assert.Equal(t, 200, performJSONRequest(t, env.App, "POST", "/api/auth/login", map[string]string{
+		"email":    "made@example.com",
+		"password": "Replaced456!",
+	}).Code)
+}
+
+func TestRoleChangeEndsSessions(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	users := env.Container.Users
+	ctx := context.Background()
+
+	owner, err := users.CreateUser(ctx, services.CreateUserInput{
+		Name:     "Only Owner",
+		Email:    "owner@example.com",
+		Password: "Password123!",
+		Role:     models.RoleSuperAdmin,
+	})
+	require.NoError(t, err)
+	_, err = users.UpdateRole(ctx, owner.ID, models.RoleUser)
+	appErr, ok := apperror.As(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, appErr.Status)
+	assert.Equal(t, i18n.ErrLastSuperadmin, appErr.Code)
+
+	registered, err := env.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Second Owner",
+		Email:    "second-owner@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	_, err = users.UpdateRole(ctx, registered.User.ID, models.RoleSuperAdmin)
+	require.NoError(t, err)
+
+	// The tokens carrying the old role stop working.
+	assert.Equal(t, 401, performAuthedRequest(t, env.App, "GET", "/api/user/profile", registered.AccessToken, nil).Code)
+	assert.Equal(t, 401, performJSONRequest(t, env.App, "POST", "/api/auth/refresh", map[string]string{
+		"refresh_token": registered.RefreshToken,
+	}).Code)
+
+	// With a second superadmin, the first can be demoted.
+	demoted, err := users.UpdateRole(ctx, owner.ID, models.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, demoted.Role)
+}
+
+// demoteConcurrently demotes two superadmins at once and checks that at most
+// one demotion succeeded and a superadmin remains.
+func demoteConcurrently(t *testing.T, ctr *container.Container) {
+	t.Helper()
+	ctx := context.Background()
+	var ids [2]uuid.UUID
+	for i := range ids {
+		admin, err := ctr.Users.CreateUser(ctx, services.CreateUserInput{
+			Name:     fmt.Sprintf("Racing Admin %d", i),
+			Email:    fmt.Sprintf("racing-%d-%s@example.com", i, uuid.NewString()[:8]),
+			Password: "Password123!",
+			Role:     models.RoleSuperAdmin,
+		})
+		require.NoError(t, err)
+		ids[i] = admin.ID
+	}
+
+	var wg sync.WaitGroup
+	var errs [2]error
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = ctr.Users.UpdateRole(ctx, id, models.RoleUser)
+		}()
+	}
+	wg.Wait()
+
+	assert.False(t, errs[0] == nil && errs[1] == nil, "both demotions succeeded")
+	superadmins, err := ctr.Store.Users().ListByRole(ctx, models.RoleSuperAdmin)
+	require.NoError(t, err)
+	assert.NotEmpty(t, superadmins)
+}
+
+func TestConcurrentDemotionsKeepASuperadmin(t *testing.T) {
+	t.Parallel()
+	for range 5 {
+		env := newTestApp(t, nil)
+		demoteConcurrently(t, env.Container)
+	}
+}
+
+func TestRevocationFromAnotherProcessReachesTheServer(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	ctx := context.Background()
+	// A second container on the same database stands in for the CLI: it
+	// does not share the server's in-memory blacklist.
+	cli, err := container.New(env.Container.Config, env.DB())
+	require.NoError(t, err)
+
+	promoted, err := env.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Promoted User",
+		Email:    "promoted@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	reset, err := env.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Reset User",
+		Email:    "reset@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	revoked, err := env.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Revoked User",
+		Email:    "revoked@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+
+	_, err = cli.Users.UpdateRole(ctx, promoted.User.ID, models.RoleAdmin)
+	require.NoError(t, err)
+	require.NoError(t, cli.Users.SetPassword(ctx, reset.User.ID, "Replaced456!"))
+	require.NoError(t, cli.Users.RevokeSessions(ctx, revoked.User.ID))
+
+	for _, token := range []string{promoted.AccessToken, reset.AccessToken, revoked.AccessToken} {
+		assert.Equal(t, 401, performAuthedRequest(t, env.App, "GET", "/api/user/profile", token, nil).Code)
+	}
+
+	// Tokens issued afterwards work.
+	login := performJSONRequest(t, env.App, "POST", "/api/auth/login", map[string]string{
+		"email":    "promoted@example.com",
+		"password": "Password123!",
+	})
+	require.Equal(t, 200, login.Code)
+	var fresh authPayload
+	require.NoError(t, json.Unmarshal(login.Body.Bytes(), &fresh))
+	assert.Equal(t, 200, performAuthedRequest(t, env.App, "GET", "/api/user/profile", fresh.AccessToken, nil).Code)
+}
+
+func TestPurgeExpiredSessions(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	ctx := context.Background()
+
+	registered, err := env.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Purge Sessions",
+		Email:    "purge-sessions@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	require.NoError(t, env.Container.Store.RefreshTokens().Create(ctx, &models.RefreshToken{
+		ID:        utils.GenerateUUID(),
+		UserID:    registered.User.ID,
+		Token:     "expired-token",
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}))
+
+	n, err := env.Container.Accounts.PurgeExpiredSessions(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	sessions, err := env.Container.Store.RefreshTokens().ListForUser(ctx, registered.User.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, registered.RefreshToken, sessions[0].Token)
+}
+
+func TestTokensSignedWithPreviousSecretStillVerify(t *testing.T) {
+	t.Parallel()
+	old := utils.NewTokenService("old-secret", time.Hour, blacklist.New())
+	token, err := old.GenerateJWTRole("user-1", models.RoleUser)
+	require.NoError(t, err)
+
+	rotated := utils.NewTokenService("new-secret", time.Hour, blacklist.New(), "old-secret")
+	claims, err := rotated.ParseJWTClaims(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+
+	_, err = utils.NewTokenService("new-secret", time.Hour, blacklist.New()).ParseJWTClaims(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func profileRequest(t *testing.T, app *fiber.App, method, token, ifMatch string, body any) *http.Response {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, "/api/user/profile", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 0, FailOnTimeout: false})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestProfileUpdateHonoursIfMatch(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, nil)
+	registered, err := app.Container.Auth.Register(context.Background(), services.RegisterInput{
+		Name:     "Versioned User",
+		Email:    "versioned@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	token := registered.AccessToken
+
+	resp := profileRequest(t, app.App, http.MethodGet, token, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	resp = profileRequest(t, app.App, http.MethodPut, token, etag, map[string]string{"name": "First Writer"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	resp = profileRequest(t, app.App, http.MethodPut, token, etag, map[string]string{"name": "Second Writer"})
+	require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	var problem errorPayload
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, i18n.ErrPreconditionFailed, problem.Code)
+
+	resp = profileRequest(t, app.App, http.MethodPut, token, `W/"2"`, map[string]string{"name": "Weak Writer"})
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp = profileRequest(t, app.App, http.MethodPut, token, "*", map[string]string{"name": "Any Writer"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	user, err := app.Container.Users.Profile(context.Background(), registered.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Any Writer", user.Name)
+	assert.Equal(t, int64(3), user.Version)
+}
+
+func TestConcurrentSavesDoNotOverwriteEachOther(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, nil)
+	users := app.Container.Store.Users()
+	ctx := context.Background()
+
+	user := models.User{ID: uuid.New(), Name: "Original", Email: "race@example.com", Username: "race"}
+	require.NoError(t, users.Create(ctx, &user))
+	assert.Equal(t, int64(1), user.Version)
+
+	first, err := users.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	second, err := users.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	first.Name = "First"
+	require.NoError(t, users.Save(ctx, first))
+	second.Name = "Second"
+	assert.ErrorIs(t, users.Save(ctx, second), repositories.ErrStale)
+	assert.Equal(t, int64(1), second.Version, "a failed save keeps the version read")
+
+	stored, err := users.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First", stored.Name)
+	assert.Equal(t, int64(2), stored.Version)
+}
+
+func TestUniqueViolationsAreConflicts(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, nil)
+	users := app.Container.Store.Users()
+	ctx := context.Background()
+
+	taken := models.User{ID: uuid.New(), Email: "taken@example.com", Username: "taken"}
+	require.NoError(t, users.Create(ctx, &taken))
+	err := users.Create(ctx, &models.User{ID: uuid.New(), Email: "taken@example.com", Username: "other"})
+	assert.ErrorIs(t, err, repositories.ErrDuplicate)
+
+	// Skip the service's own check to hit the index, as a concurrent
+	// request taking the username in between would.
+	user := models.User{ID: uuid.New(), Email: "mine@example.com", Username: "mine"}
+	require.NoError(t, users.Create(ctx, &user))
+	user.Username = "taken"
+	assert.ErrorIs(t, users.Save(ctx, &user), repositories.ErrDuplicate)
+}
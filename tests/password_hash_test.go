@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgon2idHasherUsesPHCFormat(t *testing.T) {
+	hasher := utils.DefaultArgon2idHasher()
+
+	encoded, err := hasher.Hash("Password123!")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=19456,t=2,p=1$"))
+
+	ok, err := hasher.Verify("Password123!", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("wrong", encoded)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, hasher.NeedsRehash(encoded))
+	stronger := hasher
+	stronger.Iterations = 3
+	assert.True(t, stronger.NeedsRehash(encoded))
+
+	_, err = hasher.Verify("Password123!", "$argon2id$garbage")
+	assert.ErrorIs(t, err, utils.ErrInvalidHash)
+}
+
+func TestLoginRehashesLegacyBcryptHash(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	app := env.App
+
+	legacy, err := utils.BcryptHasher{Cost: 4}.Hash("Password123!")
+	require.NoError(t, err)
+
+	user := models.User{
+		ID:       utils.GenerateUUID(),
+		Name:     "Legacy User",
+		Email:    "legacy@example.com",
+		Username: utils.GenerateUsername("Legacy User"),
+		Password: legacy,
+		Role:     models.RoleUser,
+	}
+	require.NoError(t, env.DB().Create(&user).Error)
+
+	resp := performJSONRequest(t, app, "POST", "/api/auth/login", map[string]string{
+		"email":    "legacy@example.com",
+		"password": "Password123!",
+	})
+	require.Equal(t, 200, resp.Code)
+
+	var stored models.User
+	require.NoError(t, env.DB().First(&stored, "id = ?", user.ID).Error)
+	assert.True(t, strings.HasPrefix(stored.Password, "$argon2id$"))
+	assert.True(t, utils.CheckPasswordHash(env.Container.Hasher, "Password123!", stored.Password))
+}
+
+func TestContainersHashWithTheirOwnConfiguration(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, func(cfg *config.Config) {
+		cfg.PasswordHashAlgorithm = utils.HashBcrypt
+		cfg.BcryptCost = 4
+	})
+	registered, err := env.Container.Auth.Register(context.Background(), services.RegisterInput{
+		Name:     "Bcrypt User",
+		Email:    "bcrypt@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+
+	var stored models.User
+	require.NoError(t, env.DB().First(&stored, "id = ?", registered.User.ID).Error)
+	assert.True(t, strings.HasPrefix(stored.Password, "$2a$04$"), stored.Password)
+
+	cfg := env.Container.Config
+	cfg.PasswordHashAlgorithm = "md5"
+	_, err = container.New(cfg, env.DB())
+	assert.ErrorContains(t, err, "PASSWORD_HASH_ALGORITHM")
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/passwordpolicy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicyRules(t *testing.T) {
+	policy := passwordpolicy.Policy{
+		MinLength:            8,
+		MaxBytes:             72,
+		RequireUpper:         true,
+		RequireLower:         true,
+		RequireDigit:         true,
+		DisallowPersonalInfo: true,
+	}
+
+	assert.Empty(t, policy.Validate("password", "Password123!", "jane@example.com", "Jane Doe"))
+	assert.Len(t, policy.Validate("password", "a", "jane@example.com", "Jane Doe"), 3)
+	assert.NotEmpty(t, policy.Validate("password", "Janedoe2024", "jane@example.com", "Jane Doe"))
+
+	// The minimum counts characters, the maximum bytes.
+	assert.Empty(t, policy.Validate("password", "Aé1"+strings.Repeat("é", 5)))
+	tooLong := policy.Validate("password", "Aa1"+strings.Repeat("é", 35))
+	require.Len(t, tooLong, 1)
+	assert.Equal(t, "password.too_long", tooLong[0].Code)
+	assert.Equal(t, "Must be at most 72 bytes long", tooLong[0].Message)
+}
+
+func TestPasswordPolicyBreachedRange(t *testing.T) {
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte("Summer2024"))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, digest[:5]+".txt"), []byte(digest[5:]+":42\n"), 0o644))
+
+	checker := passwordpolicy.RangeDirChecker{Dir: dir}
+	breached, err := checker.IsBreached("Summer2024")
+	require.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = checker.IsBreached("Winter2024")
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestRegisterRejectsWeakPassword(t *testing.T) {
+	t.Parallel()
+	app := setupAuthTestApp(t)
+
+	resp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Weak User",
+		"email":    "weak@example.com",
+		"password": "x",
+	})
+	require.Equal(t, 422, resp.Code)
+
+	var payload struct {
+		Code   string `json:"code"`
+		Errors []struct {
+			Field string `json:"field"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+	assert.Equal(t, "password_policy_violation", payload.Code)
+	require.NotEmpty(t, payload.Errors)
+	assert.Equal(t, "password", payload.Errors[0].Field)
+}
+
+func TestChangePasswordRejectsReuse(t *testing.T) {
+	t.Parallel()
+	app := setupAuthTestApp(t)
+
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "History User",
+		"email":    "history@example.com",
+		"password": "Password123!",
+	})
+	var registered authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &registered))
+
+	resp := performAuthedRequest(t, app, "PUT", "/api/user/password", registered.AccessToken, map[string]string{
+		"current_password": "Password123!",
+		"new_password":     "Different456!",
+		"refresh_token":    registered.RefreshToken,
+	})
+	require.Equal(t, 200, resp.Code)
+
+	// Changing the password revokes the old access token; continue with the
+	// one issued for the kept session.
+	var changed struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &changed))
+
+	resp = performAuthedRequest(t, app, "PUT", "/api/user/password", changed.AccessToken, map[string]string{
+		"current_password": "Different456!",
+		"new_password":     "Password123!",
+	})
+	assert.Equal(t, 422, resp.Code)
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorsRenderAsProblemJSON(t *testing.T) {
+	t.Parallel()
+	app := setupAuthTestApp(t)
+
+	cases := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/api/user/profile", 401, "unauthorized"},
+		{"/does-not-exist", 404, "not_found"},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", tc.path, nil), fiber.TestConfig{Timeout: 0, FailOnTimeout: false})
+		require.NoError(t, err)
+		require.Equal(t, tc.status, resp.StatusCode, tc.path)
+		assert.Equal(t, utils.MIMEProblemJSON, resp.Header.Get("Content-Type"), tc.path)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		var problem utils.ProblemDetails
+		require.NoError(t, json.Unmarshal(body, &problem))
+		assert.Equal(t, tc.status, problem.Status)
+		assert.Equal(t, tc.code, problem.Code)
+		assert.Equal(t, "/problems/"+tc.code, problem.Type)
+		assert.Equal(t, tc.path, problem.Instance)
+		assert.NotEmpty(t, problem.Title)
+	}
+}
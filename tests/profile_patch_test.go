@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchProfile(t *testing.T, app *fiber.App, token, contentType, ifMatch, body string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPatch, "/api/user/profile", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 0, FailOnTimeout: false})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	t.Parallel()
+	doc := []byte(`{"a":{"b":1},"list":[1,2],"x~y":true}`)
+
+	out, err := utils.ApplyJSONPatch(doc, []byte(`[
+		{"op":"test","path":"/a/b","value":1},
+		{"op":"add","path":"/list/1","value":9},
+		{"op":"add","path":"/list/-","value":3},
+		{"op":"remove","path":"/x~0y"},
+		{"op":"copy","from":"/a","path":"/c"},
+		{"op":"move","from":"/a/b","path":"/moved"},
+		{"op":"replace","path":"/c/b","value":"two"}
+	]`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":{},"c":{"b":"two"},"list":[1,9,2,3],"moved":1}`, string(out))
+
+	_, err = utils.ApplyJSONPatch(doc, []byte(`[{"op":"test","path":"/a/b","value":2}]`))
+	assert.ErrorIs(t, err, utils.ErrPatchTestFailed)
+	_, err = utils.ApplyJSONPatch(doc, []byte(`[{"op":"replace","path":"/missing","value":2}]`))
+	assert.ErrorIs(t, err, utils.ErrInvalidPatch)
+	_, err = utils.ApplyJSONPatch(doc, []byte(`[{"op":"move","from":"/a","path":"/a/b/c"}]`))
+	assert.ErrorIs(t, err, utils.ErrInvalidPatch)
+
+	out, err = utils.ApplyMergePatch(doc, []byte(`{"a":{"b":null,"c":2},"list":null}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":{"c":2},"x~y":true}`, string(out))
+}
+
+func TestPatchProfile(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, nil)
+	ctx := context.Background()
+	registered, err := app.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Patched User",
+		Email:    "patched@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	token := registered.AccessToken
+
+	resp := patchProfile(t, app.App, token, "application/merge-patch+json", `"1"`, `{"name":"Merged","username":"merged_user"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	resp = patchProfile(t, app.App, token, "application/json-patch+json", "",
+		`[{"op":"test","path":"/username","value":"merged_user"},{"op":"replace","path":"/name","value":""}]`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	user, err := app.Container.Users.Profile(ctx, registered.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "", user.Name)
+	assert.Equal(t, "merged_user", user.Username)
+	assert.Equal(t, int64(3), user.Version)
+
+	entries, _, err := app.Container.Audit.List(ctx, repositories.AuditEventFilter{Action: services.AuditProfileUpdate}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	var diffs []map[string]map[string]string
+	for _, entry := range entries {
+		var changes map[string]map[string]string
+		raw, err := json.Marshal(entry.Metadata["changes"])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, &changes))
+		diffs = append(diffs, changes)
+	}
+	assert.ElementsMatch(t, []map[string]map[string]string{
+		{"name": {"from": "Patched User", "to": "Merged"}, "username": {"from": registered.User.Username, "to": "merged_user"}},
+		{"name": {"from": "Merged", "to": ""}},
+	}, diffs)
+
+	// Nothing changes when a patch is refused.
+	cases := []struct {
+		contentType, body, code string
+		status                  int
+		field                   string
+	}{
+		{"application/merge-patch+json", `{"role":"admin","email":"x@example.com"}`, i18n.ErrValidationFailed, 422, "email"},
+		{"application/merge-patch+json", `{"username":null}`, i18n.ErrValidationFailed, 422, "username"},
+		{"application/json", `{"username":42}`, i18n.ErrValidationFailed, 422, "username"},
+		{"application/json-patch+json", `[{"op":"test","path":"/name","value":"Someone"}]`, i18n.ErrPatchTestFailed, 409, ""},
+		{"application/json-patch+json", `{"op":"replace"}`, i18n.ErrInvalidPatch, 400, ""},
+		{"text/plain", `{"name":"Plain"}`, i18n.ErrUnsupportedMediaType, 415, ""},
+	}
+	for _, tc := range cases {
+		resp := patchProfile(t, app.App, token, tc.contentType, "", tc.body)
+		require.Equal(t, tc.status, resp.StatusCode, tc.body)
+		var problem struct {
+			Code   string                  `json:"code"`
+			Errors []utils.ValidationError `json:"errors"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+		assert.Equal(t, tc.code, problem.Code, tc.body)
+		if tc.field != "" {
+			require.NotEmpty(t, problem.Errors, tc.body)
+			assert.Equal(t, tc.field, problem.Errors[0].Field, tc.body)
+		}
+	}
+
+	resp = patchProfile(t, app.App, token, "application/merge-patch+json", `"2"`, `{"name":"Late"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	user, err = app.Container.Users.Profile(ctx, registered.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), user.Version)
+}
+
+func TestExtendedProfileFields(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, nil)
+	ctx := context.Background()
+	registered, err := app.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Extended User",
+		Email:    "extended@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	token := registered.AccessToken
+
+	resp := profileRequest(t, app.App, http.MethodPut, token, "", map[string]any{
+		"avatar_url": "https://cdn.example.com/a.png",
+		"bio":        "Hello",
+		"locale":     "pt-BR",
+		"timezone":   "America/Sao_Paulo",
+		"phone":      "+5511987654321",
+		"metadata":   map[string]any{"theme": "dark", "beta": true},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = patchProfile(t, app.App, token, "application/merge-patch+json", "", `{"bio":null,"metadata":{"beta":null,"lang":"pt"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = patchProfile(t, app.App, token, "application/json-patch+json", "", `[{"op":"add","path":"/metadata/tags","value":["a"]}]`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = profileRequest(t, app.App, http.MethodGet, token, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var profile struct {
+		AvatarURL string         `json:"avatar_url"`
+		Bio       string         `json:"bio"`
+		Locale    string         `json:"locale"`
+		Timezone  string         `json:"timezone"`
+		Phone     string         `json:"phone"`
+		Metadata  map[string]any `json:"metadata"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&profile))
+	assert.Equal(t, "https://cdn.example.com/a.png", profile.AvatarURL)
+	assert.Equal(t, "", profile.Bio)
+	assert.Equal(t, "pt-BR", profile.Locale)
+	assert.Equal(t, "America/Sao_Paulo", profile.Timezone)
+	assert.Equal(t, "+5511987654321", profile.Phone)
+	assert.Equal(t, map[string]any{"theme": "dark", "lang": "pt", "tags": []any{"a"}}, profile.Metadata)
+
+	resp = patchProfile(t, app.App, token, "application/merge-patch+json", "",
+		`{"avatar_url":"javascript:alert(1)","timezone":"Mars/Olympus","phone":"555-1234","locale":"not a locale"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	var problem struct {
+		Errors []utils.ValidationError `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	codes := map[string]string{}
+	for _, e := range problem.Errors {
+		codes[e.Field] = e.Code
+	}
+	assert.Equal(t, map[string]string{
+		"avatar_url": i18n.ValidationURL,
+		"timezone":   i18n.ValidationTimezone,
+		"phone":      i18n.ValidationRegex,
+		"locale":     i18n.ValidationRegex,
+	}, codes)
+
+	resp = patchProfile(t, app.App, token, "application/merge-patch+json", "",
+		`{"metadata":{"blob":"`+strings.Repeat("x", 17<<10)+`"}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	// Only changed fields are checked, so a username generated before the
+	// current rules does not block other updates.
+	legacy := models.User{ID: uuid.New(), Email: "legacy@example.com", Username: "josé-legacy"}
+	require.NoError(t, app.Container.Store.Users().Create(ctx, &legacy))
+	updated, err := app.Container.Users.PatchProfile(ctx, legacy.ID, services.PatchProfileInput{
+		Format: services.MergePatch,
+		Patch:  []byte(`{"name":"José"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "José", updated.Name)
+}
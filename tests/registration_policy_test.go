@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type policyErrorPayload struct {
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+}
+
+func TestRegisterRejectedWhenClosed(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, func(cfg *config.Config) { cfg.RegistrationMode = config.RegistrationClosed }).App
+
+	resp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Closed User",
+		"email":    "closed@example.com",
+		"password": "Password123!",
+	})
+
+	require.Equal(t, 403, resp.Code)
+
+	var payload policyErrorPayload
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+	assert.Equal(t, "registration_closed", payload.Code)
+}
+
+func TestRegisterEnforcesEmailDomains(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, func(cfg *config.Config) { cfg.AllowedEmailDomains = []string{"corp.example"} }).App
+
+	resp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Outside User",
+		"email":    "outside@example.com",
+		"password": "Password123!",
+	})
+	require.Equal(t, 403, resp.Code)
+
+	var payload policyErrorPayload
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+	assert.Equal(t, "email_domain_not_allowed", payload.Code)
+
+	resp = performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Inside User",
+		"email":    "inside@corp.example",
+		"password": "Password123!",
+	})
+	assert.Equal(t, 201, resp.Code)
+}
+
+func TestRegisterInviteOnly(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, func(cfg *config.Config) { cfg.RegistrationMode = config.RegistrationInviteOnly })
+	app := env.App
+
+	resp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Uninvited User",
+		"email":    "uninvited@example.com",
+		"password": "Password123!",
+	})
+	require.Equal(t, 403, resp.Code)
+
+	var payload policyErrorPayload
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+	assert.Equal(t, "invitation_required", payload.Code)
+
+	invitation := models.Invitation{
+		ID:        utils.GenerateUUID(),
+		Code:      utils.GenerateSecureToken(16),
+		Email:     "invited@example.com",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, env.DB().Create(&invitation).Error)
+
+	resp = performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":        "Invited User",
+		"email":       "invited@example.com",
+		"password":    "Password123!",
+		"invite_code": invitation.Code,
+	})
+	require.Equal(t, 201, resp.Code)
+
+	// The invitation is single use.
+	resp = performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":        "Second User",
+		"email":       "invited@example.com",
+		"password":    "Password123!",
+		"invite_code": invitation.Code,
+	})
+	require.Equal(t, 403, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+	assert.Equal(t, "invitation_invalid", payload.Code)
+
+	// A sign-up that looked the invitation up before another redeemed it
+	// cannot redeem it again.
+	err := env.Container.Store.Invitations().MarkUsed(context.Background(), &invitation, utils.GenerateUUID(), time.Now())
+	assert.ErrorIs(t, err, repositories.ErrInvitationUsed)
+}
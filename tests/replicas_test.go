@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// openMigratedDB opens a private in-memory database with the schema applied.
+func openMigratedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.ConnectDB(config.Config{
+		DBPath: fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString()),
+	})
+	require.NoError(t, err)
+	require.NoError(t, database.MigrateDB(db))
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// The replica is a separate, empty database, standing in for one that has
+// not caught up with the primary yet.
+func TestReadsGoToReplicasUnlessPrimaryIsForced(t *testing.T) {
+	t.Parallel()
+	primary := openMigratedDB(t)
+	require.NoError(t, database.UseReplicas(primary, openMigratedDB(t)))
+
+	ctx := context.Background()
+	user := models.User{ID: uuid.New(), Name: "Lagging", Email: "lag@example.com", Username: "lag"}
+	require.NoError(t, primary.WithContext(ctx).Create(&user).Error)
+
+	var found models.User
+	assert.ErrorIs(t, primary.WithContext(ctx).First(&found, "id = ?", user.ID).Error, gorm.ErrRecordNotFound)
+	assert.NoError(t, primary.WithContext(database.WithPrimary(ctx)).First(&found, "id = ?", user.ID).Error)
+	assert.NoError(t, primary.Transaction(func(tx *gorm.DB) error {
+		return tx.First(&found, "id = ?", user.ID).Error
+	}))
+}
+
+func TestRegisterAndRefreshReadTheirWrites(t *testing.T) {
+	t.Parallel()
+	primary := openMigratedDB(t)
+	require.NoError(t, database.UseReplicas(primary, openMigratedDB(t)))
+	cfg := config.Load()
+	cfg.JWTSecret = "test-secret"
+	ctr, err := container.New(cfg, primary)
+	require.NoError(t, err)
+	auth := ctr.Auth
+	ctx := context.Background()
+
+	registered, err := auth.Register(ctx, services.RegisterInput{
+		Name:     "Replica User",
+		Email:    "replica@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+
+	refreshed, err := auth.Refresh(ctx, registered.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, registered.User.ID, refreshed.User.ID)
+}
+
+func TestConditionalProfileWritesReadThePrimary(t *testing.T) {
+	t.Parallel()
+	primary := openMigratedDB(t)
+	// The replica never catches up, so a version read from it would be stale.
+	require.NoError(t, database.UseReplicas(primary, openMigratedDB(t)))
+	cfg := config.Load()
+	cfg.JWTSecret = "test-secret"
+	ctr, err := container.New(cfg, primary)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	registered, err := ctr.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Replica User",
+		Email:    "replica-profile@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	userID := registered.User.ID
+
+	updated, err := ctr.Users.UpdateProfile(ctx, userID, services.UpdateProfileInput{
+		Name:      "Replica Renamed",
+		AvatarURL: "https://example.com/a.png",
+		IfVersion: registered.User.Version,
+	})
+	require.NoError(t, err)
+	patched, err := ctr.Users.PatchProfile(ctx, userID, services.PatchProfileInput{
+		Patch:     []byte(`{"bio":"Hello"}`),
+		IfVersion: updated.Version,
+	})
+	require.NoError(t, err)
+	cleared, err := ctr.Avatars.Delete(ctx, userID, patched.Version)
+	require.NoError(t, err)
+	assert.Empty(t, cleared.AvatarURL)
+	_, err = ctr.Users.ChangePassword(ctx, userID, services.ChangePasswordInput{
+		CurrentPassword: "Password123!",
+		NewPassword:     "NewPassword456!",
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/seeders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFixturesMergesYAMLAndJSON(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"dev/a.yaml":    {Data: []byte("users:\n  - name: Yaml User\n    email: yaml@example.com\n    password: Password123!\n    role: admin\n")},
+		"dev/b.json":    {Data: []byte(`{"users":[{"name":"Json User","email":"json@example.com","password":"Password123!","verified":true}]}`)},
+		"dev/notes.txt": {Data: []byte("ignored")},
+	}
+
+	fixtures, err := seeders.LoadFixtures(fsys, "dev")
+	require.NoError(t, err)
+	require.Len(t, fixtures.Users, 2)
+	assert.Equal(t, "yaml@example.com", fixtures.Users[0].Email)
+	assert.Equal(t, models.RoleAdmin, fixtures.Users[0].Role)
+	assert.True(t, fixtures.Users[1].Verified)
+
+	empty, err := seeders.LoadFixtures(fsys, "demo")
+	require.NoError(t, err)
+	assert.Empty(t, empty.Users)
+}
+
+func TestSeedersAreIdempotent(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	ctx := context.Background()
+	registry := seeders.Default()
+
+	ran, err := registry.Run(ctx, env.Container, seeders.EnvDemo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fixtures", "demo-users"}, ran)
+	count := countUsers(t, env.Container)
+	assert.Equal(t, int64(27), count)
+
+	_, err = registry.Run(ctx, env.Container, seeders.EnvDemo)
+	require.NoError(t, err)
+	assert.Equal(t, count, countUsers(t, env.Container))
+
+	admin, err := env.Container.Users.FindByEmail(ctx, "admin@demo.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, admin.Role)
+
+	// Seeders not targeting the environment are skipped even when named.
+	ran, err = registry.Run(ctx, env.Container, seeders.EnvDev, "demo-users")
+	require.NoError(t, err)
+	assert.Empty(t, ran)
+
+	_, err = registry.Run(ctx, env.Container, "staging")
+	assert.Error(t, err)
+	assert.Error(t, registry.Register(seeders.Seeder{Name: "fixtures", Run: func(context.Context, *container.Container, string) error { return nil }}))
+}
+
+func TestGenerateUsersCountsCommittedBatchesOnly(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	failing := *env.Container
+	failing.Store = commitFailingStore{env.Container.Store}
+
+	created, err := seeders.GenerateUsers(context.Background(), &failing, seeders.GenerateOptions{Count: 5, Seed: 3})
+	require.Error(t, err)
+	assert.Zero(t, created)
+	assert.Zero(t, countUsers(t, env.Container))
+}
+
+func TestSeedersRefuseProduction(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, func(cfg *config.Config) {
+		cfg.AppEnv = config.EnvProduction
+	})
+	ctx := context.Background()
+
+	_, err := seeders.Default().Run(ctx, env.Container, seeders.EnvDev)
+	assert.ErrorIs(t, err, seeders.ErrProduction)
+	_, err = seeders.GenerateUsers(ctx, env.Container, seeders.GenerateOptions{Count: 1})
+	assert.ErrorIs(t, err, seeders.ErrProduction)
+	err = seeders.FixturesSeeder(seeders.EmbeddedFixtures()).Run(ctx, env.Container, seeders.EnvDev)
+	assert.ErrorIs(t, err, seeders.ErrProduction)
+	assert.Zero(t, countUsers(t, env.Container))
+}
+
+func TestGenerateUsers(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	ctx := context.Background()
+
+	created, err := seeders.GenerateUsers(ctx, env.Container, seeders.GenerateOptions{Count: 30, Seed: 42, BatchSize: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 30, created)
+
+	// The same seed produces the same users, which already exist.
+	created, err = seeders.GenerateUsers(ctx, env.Container, seeders.GenerateOptions{Count: 30, Seed: 42})
+	require.NoError(t, err)
+	assert.Zero(t, created)
+	assert.Equal(t, int64(30), countUsers(t, env.Container))
+}
+
+func countUsers(t *testing.T, ctr *container.Container) int64 {
+	t.Helper()
+	var count int64
+	require.NoError(t, ctr.DB.Model(&models.User{}).Count(&count).Error)
+	return count
+}
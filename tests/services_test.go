@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthServiceWithoutHTTP(t *testing.T) {
+	t.Parallel()
+	auth := newTestApp(t, nil).Container.Auth
+	ctx := context.Background()
+
+	registered, err := auth.Register(ctx, services.RegisterInput{
+		Name:     "Service User",
+		Email:    "service@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, registered.AccessToken)
+	assert.NotEmpty(t, registered.RefreshToken)
+
+	_, err = auth.Register(ctx, services.RegisterInput{
+		Name:     "Service User",
+		Email:    "service@example.com",
+		Password: "Password123!",
+	})
+	appErr, ok := apperror.As(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, appErr.Status)
+	assert.Equal(t, i18n.ErrEmailExists, appErr.Code)
+
+	loggedIn, err := auth.Login(ctx, services.LoginInput{Email: "service@example.com", Password: "Password123!"})
+	require.NoError(t, err)
+	assert.Equal(t, registered.User.ID, loggedIn.User.ID)
+
+	_, err = auth.Login(ctx, services.LoginInput{Email: "service@example.com", Password: "wrong"})
+	appErr, ok = apperror.As(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, appErr.Status)
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ElvinEga/gofiber_starter/blacklist"
+	"github.com/ElvinEga/gofiber_starter/container"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangePasswordRevokesOtherSessions(t *testing.T) {
+	t.Parallel()
+	rec := &recordingMailer{}
+	app := newTestApp(t, nil, container.WithMailer(rec)).App
+
+	registerResp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "Revoking User",
+		"email":    "revoking@example.com",
+		"password": "Password123!",
+	})
+	var current authPayload
+	require.NoError(t, json.Unmarshal(registerResp.Body.Bytes(), &current))
+
+	loginResp := performJSONRequest(t, app, "POST", "/api/auth/login", map[string]string{
+		"email":    "revoking@example.com",
+		"password": "Password123!",
+	})
+	var other authPayload
+	require.NoError(t, json.Unmarshal(loginResp.Body.Bytes(), &other))
+
+	resp := performAuthedRequest(t, app, "PUT", "/api/user/password", current.AccessToken, map[string]string{
+		"current_password": "Password123!",
+		"new_password":     "Rotated456!",
+		"refresh_token":    current.RefreshToken,
+	})
+	require.Equal(t, 200, resp.Code)
+
+	var changed struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &changed))
+	require.NotEmpty(t, changed.AccessToken)
+
+	// Outstanding access tokens are revoked, the fresh one works.
+	assert.Equal(t, 401, performAuthedRequest(t, app, "GET", "/api/user/profile", other.AccessToken, nil).Code)
+	assert.Equal(t, 401, performAuthedRequest(t, app, "GET", "/api/user/profile", current.AccessToken, nil).Code)
+	assert.Equal(t, 200, performAuthedRequest(t, app, "GET", "/api/user/profile", changed.AccessToken, nil).Code)
+
+	// Only the named session survives.
+	assert.Equal(t, 401, performJSONRequest(t, app, "POST", "/api/auth/refresh", map[string]string{
+		"refresh_token": other.RefreshToken,
+	}).Code)
+	assert.Equal(t, 200, performJSONRequest(t, app, "POST", "/api/auth/refresh", map[string]string{
+		"refresh_token": current.RefreshToken,
+	}).Code)
+
+	notices := rec.sentTo("revoking@example.com")
+	require.Len(t, notices, 1)
+	assert.Equal(t, "Your password was changed", notices[0].Subject)
+}
+
+func TestRevokeUserComparesIssueTimesBelowTheSecond(t *testing.T) {
+	t.Parallel()
+	tokens := utils.NewTokenService("test-secret", time.Hour, blacklist.New())
+	before, err := tokens.GenerateJWTRole("user-1", "user")
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	tokens.RevokeUser("user-1")
+	role, err := tokens.GenerateJWTRole("user-1", "user")
+	require.NoError(t, err)
+	google, err := tokens.GenerateJWT("user-1")
+	require.NoError(t, err)
+
+	_, err = tokens.ParseJWTClaims(before)
+	assert.Error(t, err)
+	_, err = tokens.ParseJWTClaims(role)
+	assert.NoError(t, err)
+	_, err = tokens.ParseJWTClaims(google)
+	assert.NoError(t, err, "tokens from Google sign-in carry an issue time")
+}
+
+// commitFailingStore rolls back every transaction after its work is done,
+// as a failed commit would.
+type commitFailingStore struct {
+	repositories.Store
+}
+
+func (s commitFailingStore) Transaction(ctx context.Context, fn func(tx repositories.Store) error) error {
+	return s.Store.Transaction(ctx, func(tx repositories.Store) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return errors.New("commit failed")
+	})
+}
+
+func TestRolledBackPasswordChangeKeepsSessions(t *testing.T) {
+	t.Parallel()
+	env := newTestApp(t, nil)
+	ctx := context.Background()
+	registered, err := env.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Rolled Back",
+		Email:    "rolled-back@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+
+	deps := services.Deps{
+		Config: env.Container.Config,
+		Store:  commitFailingStore{env.Container.Store},
+		Tokens: env.Container.Tokens,
+		Mailer: env.Container.Mailer,
+		Hasher: env.Container.Hasher,
+	}
+	err = services.NewUserService(deps).SetPassword(ctx, registered.User.ID, "Replaced456!")
+	require.Error(t, err)
+
+	assert.Equal(t, 200, performAuthedRequest(t, env.App, "GET", "/api/user/profile", registered.AccessToken, nil).Code)
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/database"
+	"github.com/ElvinEga/gofiber_starter/models"
+	"github.com/ElvinEga/gofiber_starter/repositories"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWithTxRollsBackAndJoinsOuterTransaction(t *testing.T) {
+	t.Parallel()
+	db := openMigratedDB(t)
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	err := database.WithTx(ctx, db, func(tx *gorm.DB) error {
+		require.True(t, database.InTx(tx))
+		if err := tx.Create(&models.User{ID: uuid.New(), Email: "outer@example.com", Username: "outer"}).Error; err != nil {
+			return err
+		}
+		return database.WithTx(ctx, tx, func(inner *gorm.DB) error {
+			if err := inner.Create(&models.User{ID: uuid.New(), Email: "inner@example.com", Username: "inner"}).Error; err != nil {
+				return err
+			}
+			return errBoom
+		})
+	})
+	assert.ErrorIs(t, err, errBoom)
+	assert.False(t, database.InTx(db))
+
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Count(&count).Error)
+	assert.Zero(t, count, "both writes are rolled back")
+}
+
+func TestRegisterStoresNothingWhenTheSessionCannotBeStored(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, nil)
+	require.NoError(t, app.DB().Migrator().DropTable("refresh_tokens"))
+	ctx := context.Background()
+
+	_, err := app.Container.Auth.Register(ctx, services.RegisterInput{
+		Name:     "Half Done",
+		Email:    "half@example.com",
+		Password: "Password123!",
+	})
+	appErr, ok := apperror.As(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusInternalServerError, appErr.Status)
+
+	_, err = app.Container.Store.Users().FindByEmail(ctx, "half@example.com")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+func TestRefreshTokenRotatesOnlyOnce(t *testing.T) {
+	t.Parallel()
+	app := newTestApp(t, nil)
+	auth := app.Container.Auth
+	ctx := context.Background()
+
+	registered, err := auth.Register(ctx, services.RegisterInput{
+		Name:     "Rotating User",
+		Email:    "rotate@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+
+	_, err = auth.Refresh(ctx, registered.RefreshToken)
+	require.NoError(t, err)
+	_, err = auth.Refresh(ctx, registered.RefreshToken)
+	appErr, ok := apperror.As(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, appErr.Status)
+
+	tokens, err := app.Container.Store.RefreshTokens().ListForUser(ctx, registered.User.ID)
+	require.NoError(t, err)
+	assert.Len(t, tokens, 1)
+
+	stale := models.RefreshToken{ID: uuid.New()}
+	assert.ErrorIs(t, app.Container.Store.RefreshTokens().Delete(ctx, &stale), repositories.ErrNotFound)
+}
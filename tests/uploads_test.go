@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/ElvinEga/gofiber_starter/services"
+	"github.com/ElvinEga/gofiber_starter/storage"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	} else {
+		require.NoError(t, png.Encode(&buf, img))
+	}
+	return buf.Bytes()
+}
+
+func uploadAvatar(t *testing.T, app *fiber.App, token, field string, data []byte) *http.Response {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(field, "upload.bin")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/avatar", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 0, FailOnTimeout: false})
+	require.NoError(t, err)
+	return resp
+}
+
+type avatarPayload struct {
+	Data struct {
+		AvatarURL          string `json:"avatar_url"`
+		AvatarThumbnailURL string `json:"avatar_thumbnail_url"`
+	} `json:"data"`
+}
+
+func decodeAvatar(t *testing.T, resp *http.Response) avatarPayload {
+	t.Helper()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var payload avatarPayload
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+	return payload
+}
+
+func TestAvatarUploadToLocalStorage(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	app := newTestApp(t, func(cfg *config.Config) {
+		cfg.StorageDriver = config.StorageLocal
+		cfg.UploadDir = dir
+		cfg.StoragePublicURL = "https://cdn.example.com/uploads"
+		cfg.AvatarMaxBytes = 256 * 1024
+	})
+	registered, err := app.Container.Auth.Register(context.Background(), services.RegisterInput{
+		Name:     "Avatar User",
+		Email:    "avatar@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+	token := registered.AccessToken
+	localPath := func(url string) string {
+		return filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(url, "https://cdn.example.com/uploads/")))
+	}
+
+	first := decodeAvatar(t, uploadAvatar(t, app.App, token, "avatar", testImage(t, "png", 800, 600)))
+	assert.True(t, strings.HasSuffix(first.Data.AvatarURL, ".png"), first.Data.AvatarURL)
+	for url, side := range map[string]int{first.Data.AvatarURL: 512, first.Data.AvatarThumbnailURL: 128} {
+		file, err := os.Open(localPath(url))
+		require.NoError(t, err)
+		cfg, format, err := image.DecodeConfig(file)
+		file.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "png", format)
+		assert.Equal(t, side, cfg.Width)
+		assert.Equal(t, side, cfg.Height)
+	}
+
+	// Files are served under /uploads.
+	req := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(first.Data.AvatarURL, "https://cdn.example.com"), nil)
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 0, FailOnTimeout: false})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+
+	// A new upload replaces the files of the previous one.
+	second := decodeAvatar(t, uploadAvatar(t, app.App, token, "avatar", testImage(t, "jpeg", 64, 64)))
+	assert.True(t, strings.HasSuffix(second.Data.AvatarURL, ".jpg"), second.Data.AvatarURL)
+	assert.NoFileExists(t, localPath(first.Data.AvatarURL))
+	assert.NoFileExists(t, localPath(first.Data.AvatarThumbnailURL))
+	assert.FileExists(t, localPath(second.Data.AvatarURL))
+
+	refusals := []struct {
+		field  string
+		data   []byte
+		status int
+		code   string
+	}{
+		{"document", testImage(t, "png", 8, 8), http.StatusBadRequest, i18n.ErrFileRequired},
+		{"avatar", []byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"), http.StatusUnsupportedMediaType, i18n.ErrUnsupportedFileType},
+		{"avatar", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...), http.StatusUnprocessableEntity, i18n.ErrInvalidImage},
+		{"avatar", append([]byte("GIF89a"), make([]byte, 300*1024)...), http.StatusRequestEntityTooLarge, i18n.ErrFileTooLarge},
+	}
+	for _, tc := range refusals {
+		resp := uploadAvatar(t, app.App, token, tc.field, tc.data)
+		require.Equal(t, tc.status, resp.StatusCode, tc.code)
+		var problem errorPayload
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+		assert.Equal(t, tc.code, problem.Code)
+	}
+
+	// Pointing avatar_url elsewhere drops the uploaded files.
+	resp = patchProfile(t, app.App, token, "application/merge-patch+json", "", `{"avatar_url":"https://images.example.com/me.png"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoFileExists(t, localPath(second.Data.AvatarURL))
+	user, err := app.Container.Users.Profile(context.Background(), registered.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://images.example.com/me.png", user.AvatarURL)
+	assert.Empty(t, user.AvatarThumbnailURL)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/user/avatar", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req, fiber.TestConfig{Timeout: 0, FailOnTimeout: false})
+	require.NoError(t, err)
+	assert.Empty(t, decodeAvatar(t, resp).Data.AvatarURL)
+}
+
+// fakeS3 is a stand-in for an S3 bucket addressed path-style.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=test-key/") ||
+			!strings.Contains(auth, "/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") ||
+			r.Header.Get("X-Amz-Date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			fake.objects[r.URL.Path] = body
+			fake.types[r.URL.Path] = r.Header.Get("Content-Type")
+		case http.MethodDelete:
+			if _, ok := fake.objects[r.URL.Path]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(fake.objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func TestS3Storage(t *testing.T) {
+	t.Parallel()
+	fake, server := newFakeS3(t)
+	s3 := storage.NewS3Storage(storage.S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "media",
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+		ForcePathStyle:  true,
+	})
+	ctx := context.Background()
+
+	require.NoError(t, s3.Put(ctx, "docs/a b.txt", strings.NewReader("hello"), 5, "text/plain"))
+	assert.Equal(t, []byte("hello"), fake.objects["/media/docs/a b.txt"])
+	assert.Equal(t, "text/plain", fake.types["/media/docs/a b.txt"])
+	assert.Equal(t, server.URL+"/media/docs/a%20b.txt", s3.URL("docs/a b.txt"))
+
+	require.NoError(t, s3.Delete(ctx, "docs/a b.txt"))
+	assert.Empty(t, fake.objects)
+	assert.NoError(t, s3.Delete(ctx, "docs/a b.txt"), "deleting a missing object succeeds")
+	assert.ErrorIs(t, s3.Put(ctx, "../escape", strings.NewReader(""), 0, "text/plain"), storage.ErrInvalidKey)
+
+	denied := storage.NewS3Storage(storage.S3Config{Endpoint: server.URL, Region: "eu-west-1", Bucket: "media", AccessKeyID: "other", ForcePathStyle: true})
+	assert.ErrorContains(t, denied.Put(ctx, "x", strings.NewReader("x"), 1, "text/plain"), "403")
+
+	virtual := storage.NewS3Storage(storage.S3Config{Region: "eu-west-1", Bucket: "media"})
+	assert.Equal(t, "https://media.s3.eu-west-1.amazonaws.com/avatars/a.png", virtual.URL("avatars/a.png"))
+}
+
+func TestAvatarUploadToS3(t *testing.T) {
+	t.Parallel()
+	fake, server := newFakeS3(t)
+	app := newTestApp(t, func(cfg *config.Config) {
+		cfg.StorageDriver = config.StorageS3
+		cfg.S3Endpoint = server.URL
+		cfg.S3Region = "eu-west-1"
+		cfg.S3Bucket = "media"
+		cfg.S3AccessKeyID = "test-key"
+		cfg.S3SecretAccessKey = "test-secret"
+		cfg.S3ForcePathStyle = true
+		cfg.StoragePublicURL = "https://media.example.com"
+	})
+	registered, err := app.Container.Auth.Register(context.Background(), services.RegisterInput{
+		Name:     "Cloud User",
+		Email:    "cloud@example.com",
+		Password: "Password123!",
+	})
+	require.NoError(t, err)
+
+	payload := decodeAvatar(t, uploadAvatar(t, app.App, registered.AccessToken, "avatar", testImage(t, "jpeg", 300, 200)))
+	require.True(t, strings.HasPrefix(payload.Data.AvatarURL, "https://media.example.com/avatars/"+registered.User.ID.String()+"/"))
+	key := "/media/" + strings.TrimPrefix(payload.Data.AvatarURL, "https://media.example.com/")
+	require.Contains(t, fake.objects, key)
+	assert.Equal(t, "image/jpeg", fake.types[key])
+	assert.Len(t, fake.objects, 2)
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ElvinEga/gofiber_starter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type addressInput struct {
+	City string `json:"city" validate:"required"`
+}
+
+type signupInput struct {
+	Email           string         `json:"email" validate:"required,email"`
+	Password        string         `json:"password" validate:"required,min=8,max=16"`
+	ConfirmPassword string         `json:"confirm_password" validate:"required,eqfield=Password"`
+	Plan            string         `json:"plan" validate:"oneof=free pro"`
+	Age             int            `json:"age" validate:"min=18,max=130"`
+	Referrer        string         `json:"referrer" validate:"uuid"`
+	Handle          string         `json:"handle" validate:"regex=^[a-z]{2,4}$"`
+	Address         addressInput   `json:"address"`
+	Tags            []string       `json:"tags" validate:"max=3,dive,min=2"`
+	Contacts        []addressInput `json:"contacts"`
+}
+
+func fieldsOf(result utils.ValidationResult) []string {
+	fields := make([]string, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	return fields
+}
+
+func TestValidatorAcceptsValidInput(t *testing.T) {
+	result := utils.NewValidator().Validate(&signupInput{
+		Email:           "valid@example.com",
+		Password:        "Password123",
+		ConfirmPassword: "Password123",
+		Plan:            "pro",
+		Age:             30,
+		Referrer:        "0b9d6ab4-3f5c-4f7e-9a39-3b1e7a0f8c11",
+		Handle:          "abc",
+		Address:         addressInput{City: "Nairobi"},
+		Tags:            []string{"go", "api"},
+		Contacts:        []addressInput{{City: "Mombasa"}},
+	})
+
+	assert.True(t, result.Valid, result.Errors)
+}
+
+func TestValidatorReportsEveryRule(t *testing.T) {
+	result := utils.NewValidator().Validate(&signupInput{
+		Email:           "not-an-email",
+		Password:        "short",
+		ConfirmPassword: "different",
+		Plan:            "enterprise",
+		Age:             12,
+		Referrer:        "nope",
+		Handle:          "ABC",
+		Tags:            []string{"go", "x"},
+		Contacts:        []addressInput{{}},
+	})
+
+	assert.False(t, result.Valid)
+	assert.ElementsMatch(t, []string{
+		"email", "password", "confirm_password", "plan", "age",
+		"referrer", "handle", "address.city", "tags[1]", "contacts[0].city",
+	}, fieldsOf(result))
+}
+
+func TestValidatorSkipsEmptyOptionalFields(t *testing.T) {
+	type optional struct {
+		Nickname string `json:"nickname" validate:"min=3"`
+		Name     string `json:"name" validate:"required"`
+	}
+
+	result := utils.NewValidator().Validate(optional{})
+	assert.Equal(t, []string{"name"}, fieldsOf(result))
+}
+
+func TestRegisterReturnsValidationErrors(t *testing.T) {
+	t.Parallel()
+	app := setupAuthTestApp(t)
+
+	resp := performJSONRequest(t, app, "POST", "/api/auth/register", map[string]string{
+		"name":     "",
+		"email":    "not-an-email",
+		"password": "Password123!",
+	})
+	require.Equal(t, 422, resp.Code)
+
+	var payload struct {
+		Status int                     `json:"status"`
+		Errors []utils.ValidationError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+	assert.Equal(t, 422, payload.Status)
+
+	fields := make([]string, 0, len(payload.Errors))
+	for _, e := range payload.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{"email", "name"}, fields)
+}
@@ -0,0 +1,104 @@
+package uploads
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder with image.Decode
+	"image/jpeg"
+	"image/png"
+)
+
+// ErrInvalidImage is returned for data that does not decode as an image of
+// an accepted type and size.
+var ErrInvalidImage = errors.New("invalid image")
+
+// ImageTypes are the content types DecodeImage accepts. Animated GIFs are
+// reduced to their first frame.
+var ImageTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// MaxImagePixels bounds the dimensions of decoded images, so a small file
+// cannot expand into a huge bitmap.
+const MaxImagePixels = 25_000_000
+
+// DecodeImage decodes a JPEG, PNG or GIF after checking its dimensions, and
+// returns the image with its format name.
+func DecodeImage(data []byte) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, "", fmt.Errorf("%w: %dx%d pixels", ErrInvalidImage, cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	return img, format, nil
+}
+
+// Square crops img to a centred square and scales it to size pixels per
+// side. Images smaller than size are not enlarged.
+func Square(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+
+	crop := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(crop, crop.Bounds(), img, origin, draw.Src)
+	if side <= size {
+		return crop
+	}
+	return downscale(crop, size)
+}
+
+// downscale shrinks a square image to size pixels per side, averaging the
+// source pixels that fall in each destination pixel. Averaging premultiplied
+// values keeps transparent edges from darkening.
+func downscale(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := y*side/size, (y+1)*side/size
+		for x := 0; x < size; x++ {
+			x0, x1 := x*side/size, (x+1)*side/size
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					b += uint64(row[i+2])
+					a += uint64(row[i+3])
+					n++
+				}
+			}
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// Encode encodes img as JPEG when it came from a JPEG, which has no
+// transparency to lose, and as PNG otherwise. It returns the encoded bytes,
+// their content type and a file extension.
+func Encode(img image.Image, format string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/jpeg", ".jpg", nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/png", ".png", nil
+}
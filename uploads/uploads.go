@@ -0,0 +1,46 @@
+// Package uploads reads files sent by clients and prepares images for
+// storage. The content type is always detected from the data, never taken
+// from the client.
+package uploads
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrTooLarge is returned for files over the size limit.
+	ErrTooLarge = errors.New("file too large")
+	// ErrUnsupportedType is returned for files whose detected content type
+	// is not accepted.
+	ErrUnsupportedType = errors.New("unsupported file type")
+)
+
+// ReadLimited reads r to the end, failing with ErrTooLarge as soon as more
+// than max bytes arrive.
+func ReadLimited(r io.Reader, max int64) ([]byte, error) {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > max {
+		return nil, ErrTooLarge
+	}
+	return buf.Bytes(), nil
+}
+
+// Sniff detects the content type of data from its first bytes and returns
+// it when it is one of allowed.
+func Sniff(data []byte, allowed ...string) (string, error) {
+	detected := http.DetectContentType(data)
+	for _, contentType := range allowed {
+		if detected == contentType {
+			return detected, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedType, detected)
+}
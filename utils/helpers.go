@@ -1,16 +1,16 @@
 package utils
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/ElvinEga/gofiber_starter/config"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -29,19 +29,26 @@ func GenerateUsername(name string) string {
 	return fmt.Sprintf("%s%s", base, hex.EncodeToString(b))
 }
 
-// Google OAuth2 configuration.
-var googleOAuthConfig = &oauth2.Config{
-	ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-	ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-	RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
-	Scopes:       []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"},
-	Endpoint:     google.Endpoint,
+// GoogleOAuth signs users in with Google OAuth2.
+type GoogleOAuth struct {
+	config *oauth2.Config
 }
 
-// GetGoogleOAuthURL returns the URL for Google OAuth login.
-func GetGoogleOAuthURL() string {
+// NewGoogleOAuth returns a GoogleOAuth for the configured client credentials.
+func NewGoogleOAuth(cfg config.Config) *GoogleOAuth {
+	return &GoogleOAuth{config: &oauth2.Config{
+		ClientID:     cfg.GoogleClientID,
+		ClientSecret: cfg.GoogleClientSecret,
+		RedirectURL:  cfg.GoogleRedirectURL,
+		Scopes:       []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"},
+		Endpoint:     google.Endpoint,
+	}}
+}
+
+// AuthCodeURL returns the URL for Google OAuth login.
+func (g *GoogleOAuth) AuthCodeURL() string {
 	state := generateState() // Optionally generate a random state string for security.
-	return googleOAuthConfig.AuthCodeURL(state)
+	return g.config.AuthCodeURL(state)
 }
 
 // generateState creates a random state string.
@@ -51,33 +58,23 @@ func generateState() string {
 	return hex.EncodeToString(b)
 }
 
-func HashPassword(password string) string {
-	bytes, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes)
-}
-
-// CheckPasswordHash compares the hashed password with the plain password.
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
-
 // GoogleUserInfo holds data returned from Google.
 type GoogleUserInfo struct {
 	ID      string `json:"id"`
 	Email   string `json:"email"`
 	Name    string `json:"name"`
 	Picture string `json:"picture"`
+	Locale  string `json:"locale"`
 }
 
-// GetGoogleUserInfo exchanges code for a token and fetches user info from Google.
-func GetGoogleUserInfo(code string) (*GoogleUserInfo, error) {
-	token, err := googleOAuthConfig.Exchange(oauth2.NoContext, code)
+// UserInfo exchanges code for a token and fetches user info from Google.
+func (g *GoogleOAuth) UserInfo(ctx context.Context, code string) (*GoogleUserInfo, error) {
+	token, err := g.config.Exchange(ctx, code)
 	if err != nil {
 		return nil, err
 	}
 
-	client := googleOAuthConfig.Client(oauth2.NoContext, token)
+	client := g.config.Client(ctx, token)
 	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
 	if err != nil {
 		return nil, err
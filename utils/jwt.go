@@ -2,7 +2,6 @@ package utils
 
 import (
 	"errors"
-	"os"
 	"strings"
 	"time"
 
@@ -12,98 +11,179 @@ import (
 	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+func init() {
+	// Token times carry microseconds, so a per-user revocation can tell the
+	// tokens issued just before it from those issued just after it in the
+	// same second.
+	jwt.TimePrecision = time.Microsecond
+}
 
 type JWTClaims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID string       `json:"user_id"`
+	Role   string       `json:"role"`
+	Act    *ActorClaims `json:"act,omitempty"`
+	// PasswordChange limits the token to changing the password.
+	PasswordChange bool `json:"pwd_change,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userId string) (string, error) {
-	claims := jwt.MapClaims{
-		"userId": userId,
-		"exp":    time.Now().Add(time.Hour * 72).Unix(),
+// ActorClaims identifies the party acting on behalf of the subject (RFC 8693),
+// set on tokens issued through admin impersonation.
+type ActorClaims struct {
+	Sub string `json:"sub"`
+}
+
+// TokenService signs JWTs with the current secret, verifies them against the
+// current and previous secrets and checks them against its blacklist.
+type TokenService struct {
+	secret    []byte
+	keys      jwt.VerificationKeySet
+	ttl       time.Duration
+	blacklist *blacklist.Blacklist
+}
+
+// NewTokenService returns a TokenService signing access tokens valid for ttl
+// with secret. Tokens signed with one of the previous secrets are still
+// accepted, so the secret can be rotated without signing everyone out.
+// Revocations are recorded in bl.
+func NewTokenService(secret string, ttl time.Duration, bl *blacklist.Blacklist, previous ...string) *TokenService {
+	s := &TokenService{secret: []byte(secret), ttl: ttl, blacklist: bl}
+	s.keys.Keys = append(s.keys.Keys, s.secret)
+	for _, p := range previous {
+		s.keys.Keys = append(s.keys.Keys, []byte(p))
 	}
+	return s
+}
+
+// TTL is the lifetime of regular access tokens.
+func (s *TokenService) TTL() time.Duration {
+	return s.ttl
+}
+
+func (s *TokenService) sign(claims jwt.Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString(s.secret)
+}
+
+func (s *TokenService) keyFunc(*jwt.Token) (interface{}, error) {
+	return s.keys, nil
+}
 
+// GenerateJWT issues a role-less token, as handed out after Google sign-in.
+func (s *TokenService) GenerateJWT(userID string) (string, error) {
+	now := time.Now()
+	return s.sign(jwt.MapClaims{
+		"user_id": userID,
+		"iat":     jwt.NewNumericDate(now),
+		"exp":     jwt.NewNumericDate(now.Add(s.ttl)),
+	})
 }
-func GenerateJWTRole(userID string, role string) (string, error) {
-	claims := jwt.MapClaims{
+
+func (s *TokenService) GenerateJWTRole(userID string, role string) (string, error) {
+	now := time.Now()
+	return s.sign(jwt.MapClaims{
 		"user_id": userID,
 		"role":    role,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+		"iat":     jwt.NewNumericDate(now),
+		"exp":     jwt.NewNumericDate(now.Add(s.ttl)),
+	})
+}
+
+// GeneratePasswordChangeJWT issues an access token for a user who must change
+// their password before doing anything else.
+func (s *TokenService) GeneratePasswordChangeJWT(userID string, role string) (string, error) {
+	now := time.Now()
+	return s.sign(jwt.MapClaims{
+		"user_id":    userID,
+		"role":       role,
+		"pwd_change": true,
+		"iat":        jwt.NewNumericDate(now),
+		"exp":        jwt.NewNumericDate(now.Add(s.ttl)),
+	})
+}
+
+// GenerateImpersonationJWT issues a short-lived access token for the target
+// user carrying the impersonator in the "act" claim.
+func (s *TokenService) GenerateImpersonationJWT(userID, role, impersonatorID string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	signed, err := s.sign(JWTClaims{
+		UserID: userID,
+		Role:   role,
+		Act:    &ActorClaims{Sub: impersonatorID},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+		},
+	})
+	return signed, expiresAt, err
 }
 
-func VerifyJWT(c fiber.Ctx) (userID string, role string, err error) {
+func (s *TokenService) GenerateRefreshToken() (string, error) {
+	return s.sign(jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)), // 7 days
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        uuid.NewString(),
+	})
+}
+
+// VerifyJWTClaims validates the bearer token of the request and returns its claims.
+func (s *TokenService) VerifyJWTClaims(c fiber.Ctx) (*JWTClaims, error) {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return "", "", errors.New("missing token")
+		return nil, errors.New("missing token")
 	}
 
 	tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 	if tokenStr == authHeader {
-		return "", "", errors.New("invalid token format")
+		return nil, errors.New("invalid token format")
 	}
 
-	// Check blacklist first
-	if blacklist.IsBlacklisted(tokenStr) {
-		return "", "", errors.New("token revoked")
-	}
+	return s.ParseJWTClaims(tokenStr)
+}
 
-	token, err := jwt.ParseWithClaims(tokenStr, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+// ParseJWTClaims validates a raw access token, including blacklist and
+// per-user revocation checks, and returns its claims.
+func (s *TokenService) ParseJWTClaims(tokenStr string) (*JWTClaims, error) {
+	if s.blacklist.IsBlacklisted(tokenStr) {
+		return nil, errors.New("token revoked")
+	}
 
+	token, err := jwt.ParseWithClaims(tokenStr, &JWTClaims{}, s.keyFunc)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims.UserID, claims.Role, nil
+		// A token without an issue time predates every revocation.
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		if s.blacklist.IsUserRevoked(claims.UserID, issuedAt) {
+			return nil, errors.New("token revoked")
+		}
+		return claims, nil
 	}
 
-	return "", "", errors.New("invalid token claims")
+	return nil, errors.New("invalid token claims")
 }
-func VerifyJWTRole(c fiber.Ctx) (userID string, role string, err error) {
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return "", "", errors.New("Missing token")
-	}
-
-	tokenStr := authHeader[len("Bearer "):]
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
 
+// Revoke blacklists a validly signed token until it expires.
+func (s *TokenService) Revoke(tokenStr string) error {
+	token, err := jwt.Parse(tokenStr, s.keyFunc)
 	if err != nil || !token.Valid {
-		return "", "", errors.New("Invalid token")
+		return errors.New("invalid token")
 	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", "", errors.New("Invalid token claims")
-	}
-
-	id, okID := claims["user_id"].(string)
-	roleStr, okRole := claims["role"].(string)
-	if !okID || !okRole {
-		return "", "", errors.New("Invalid token data")
+	exp, err := token.Claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return errors.New("invalid token claims")
 	}
-
-	return id, roleStr, nil
+	s.blacklist.Add(tokenStr, exp.Time)
+	return nil
 }
 
-func GenerateRefreshToken() (string, error) {
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)), // 7 days
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		ID:        uuid.NewString(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+// RevokeUser invalidates every access token of the user issued so far.
+func (s *TokenService) RevokeUser(userID string) {
+	s.blacklist.RevokeUser(userID, s.ttl)
 }
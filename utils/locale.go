@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Locale returns the locale negotiated for the request, falling back to the
+// Accept-Language header when the locale middleware has not run.
+func Locale(c fiber.Ctx) string {
+	if locale, ok := c.Locals("locale").(string); ok && locale != "" {
+		return locale
+	}
+	return i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+}
+
+// LocalizeValidationErrors translates validation messages into locale. Errors
+// without a catalog entry keep their original message.
+func LocalizeValidationErrors(locale string, errs []ValidationError) []ValidationError {
+	localized := make([]ValidationError, len(errs))
+	for i, e := range errs {
+		localized[i] = e
+		if _, ok := i18n.Lookup(locale, e.Code); ok {
+			localized[i].Message = i18n.T(locale, e.Code, e.Params)
+		}
+	}
+	return localized
+}
@@ -8,8 +8,7 @@ import (
 
 // PaginationResponse formats a response with pagination metadata.
 func PaginationResponse(c fiber.Ctx, data interface{}, totalCount int64) fiber.Map {
-	page := parsePositiveInt(c.Query("page"), 1)
-	limit := parsePositiveInt(c.Query("limit"), 10)
+	page, limit, _ := GetPagination(c)
 	totalPages := (totalCount + int64(limit) - 1) / int64(limit)
 
 	return fiber.Map{
@@ -23,6 +22,17 @@ func PaginationResponse(c fiber.Ctx, data interface{}, totalCount int64) fiber.M
 	}
 }
 
+// GetPagination reads the page and limit query parameters and returns them
+// together with the matching row offset. The limit is capped at 100.
+func GetPagination(c fiber.Ctx) (page, limit, offset int) {
+	page = parsePositiveInt(c.Query("page"), 1)
+	limit = parsePositiveInt(c.Query("limit"), 10)
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit, (page - 1) * limit
+}
+
 func parsePositiveInt(value string, fallback int) int {
 	parsed, err := strconv.Atoi(value)
 	if err != nil || parsed <= 0 {
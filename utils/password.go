@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ElvinEga/gofiber_starter/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported password hashing algorithms.
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+var (
+	ErrUnknownHashFormat = errors.New("unknown password hash format")
+	ErrInvalidHash       = errors.New("malformed password hash")
+)
+
+// PasswordHasher hashes passwords into self-describing PHC strings.
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches the encoded hash.
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash reports whether encoded was produced with different
+	// parameters than the hasher currently uses.
+	NeedsRehash(encoded string) bool
+}
+
+// Argon2idHasher implements PasswordHasher with argon2id, encoding hashes as
+// $argon2id$v=19$m=<KiB>,t=<iterations>,p=<parallelism>$<salt>$<key>.
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idHasher uses the OWASP recommended baseline parameters.
+func DefaultArgon2idHasher() Argon2idHasher {
+	return Argon2idHasher{Memory: 19456, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+func (h Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.Memory ||
+		params.Iterations != h.Iterations ||
+		params.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength ||
+		uint32(len(key)) != h.KeyLength
+}
+
+func decodeArgon2id(encoded string) (Argon2idHasher, []byte, []byte, error) {
+	var params Argon2idHasher
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != HashArgon2id {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+// BcryptHasher implements PasswordHasher with bcrypt ($2a$/$2b$ strings).
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+func (h BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
+
+// MultiHasher hashes with a preferred algorithm while still verifying hashes
+// produced by any supported algorithm, so stored hashes can be upgraded
+// transparently on the next successful login.
+type MultiHasher struct {
+	Preferred string
+	Argon2id  Argon2idHasher
+	Bcrypt    BcryptHasher
+}
+
+func (m MultiHasher) Hash(password string) (string, error) {
+	hasher, err := m.byName(m.Preferred)
+	if err != nil {
+		return "", err
+	}
+	return hasher.Hash(password)
+}
+
+func (m MultiHasher) Verify(password, encoded string) (bool, error) {
+	hasher, err := m.byName(hashAlgorithm(encoded))
+	if err != nil {
+		return false, err
+	}
+	return hasher.Verify(password, encoded)
+}
+
+func (m MultiHasher) NeedsRehash(encoded string) bool {
+	if hashAlgorithm(encoded) != m.Preferred {
+		return true
+	}
+	hasher, err := m.byName(m.Preferred)
+	return err != nil || hasher.NeedsRehash(encoded)
+}
+
+func (m MultiHasher) byName(name string) (PasswordHasher, error) {
+	switch name {
+	case HashArgon2id:
+		return m.Argon2id, nil
+	case HashBcrypt:
+		return m.Bcrypt, nil
+	}
+	return nil, ErrUnknownHashFormat
+}
+
+// hashAlgorithm identifies the algorithm of an encoded hash from its prefix.
+func hashAlgorithm(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return HashArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return HashBcrypt
+	}
+	return ""
+}
+
+// NewPasswordHasher builds the hasher described by the configuration.
+func NewPasswordHasher(cfg config.Config) (PasswordHasher, error) {
+	hasher := MultiHasher{
+		Preferred: cfg.PasswordHashAlgorithm,
+		Argon2id: Argon2idHasher{
+			Memory:      uint32(cfg.Argon2MemoryKiB),
+			Iterations:  uint32(cfg.Argon2Iterations),
+			Parallelism: uint8(cfg.Argon2Parallelism),
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+		Bcrypt: BcryptHasher{Cost: cfg.BcryptCost},
+	}
+	if _, err := hasher.byName(hasher.Preferred); err != nil {
+		return nil, fmt.Errorf("unsupported PASSWORD_HASH_ALGORITHM %q", cfg.PasswordHashAlgorithm)
+	}
+	if hasher.Argon2id.Memory == 0 || hasher.Argon2id.Iterations == 0 || hasher.Argon2id.Parallelism == 0 {
+		return nil, errors.New("argon2id parameters must be positive")
+	}
+	if hasher.Bcrypt.Cost < bcrypt.MinCost || hasher.Bcrypt.Cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return hasher, nil
+}
+
+// CheckPasswordHash compares the hashed password with the plain password
+// using hasher. Malformed or unknown hashes never match.
+func CheckPasswordHash(hasher PasswordHasher, password, hash string) bool {
+	ok, err := hasher.Verify(password, hash)
+	return err == nil && ok
+}
@@ -1,26 +1,83 @@
 package utils
 
 import (
+	"errors"
+	"log"
+
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
 	"github.com/gofiber/fiber/v3"
 )
 
-type ErrorResponse struct {
-	Status  string      `json:"status"`
-	Message string      `json:"message"`
-	Details interface{} `json:"details,omitempty"`
+// MIMEProblemJSON is the media type of RFC 7807 problem documents.
+const MIMEProblemJSON = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem document. Code repeats the problem
+// type as a bare identifier; Errors lists per-field validation failures.
+type ProblemDetails struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Errors   []ValidationError `json:"errors,omitempty"`
+	Details  interface{}       `json:"details,omitempty"`
+}
+
+// statusCodes maps framework errors (unknown routes, oversized bodies, ...)
+// to problem codes.
+var statusCodes = map[int]string{
+	fiber.StatusBadRequest:            i18n.ErrBadRequest,
+	fiber.StatusUnauthorized:          i18n.ErrUnauthorized,
+	fiber.StatusForbidden:             i18n.ErrForbidden,
+	fiber.StatusNotFound:              i18n.ErrNotFound,
+	fiber.StatusMethodNotAllowed:      i18n.ErrMethodNotAllowed,
+	fiber.StatusRequestEntityTooLarge: i18n.ErrRequestTooLarge,
+	fiber.StatusUnsupportedMediaType:  i18n.ErrUnsupportedMediaType,
+	fiber.StatusUnprocessableEntity:   i18n.ErrValidationFailed,
+	fiber.StatusTooManyRequests:       i18n.ErrTooManyRequests,
 }
 
-func HandleError(c fiber.Ctx, status int, message string, details ...interface{}) error {
-	response := ErrorResponse{
-		Status:  "error",
-		Message: message,
+// ErrorHandler is the application-wide fiber.ErrorHandler. It renders
+// apperror.Error values, fiber errors and unexpected errors as
+// application/problem+json, translated into the request locale.
+func ErrorHandler(c fiber.Ctx, err error) error {
+	appErr, ok := apperror.As(err)
+	if !ok {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			code, known := statusCodes[fiberErr.Code]
+			if !known {
+				code = i18n.ErrBadRequest
+				if fiberErr.Code >= fiber.StatusInternalServerError {
+					code = i18n.ErrInternal
+				}
+			}
+			appErr = apperror.New(fiberErr.Code, code)
+		} else {
+			appErr = apperror.Internal(i18n.ErrInternal, err)
+		}
+	}
+	if appErr.Status >= fiber.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Method(), c.Path(), err)
 	}
 
-	if len(details) > 0 {
-		response.Details = details[0]
+	locale := Locale(c)
+	problem := ProblemDetails{
+		Type:     "/problems/" + appErr.Code,
+		Title:    i18n.T(locale, appErr.Code, nil),
+		Status:   appErr.Status,
+		Instance: c.OriginalURL(),
+		Code:     appErr.Code,
+	}
+	if errs, ok := appErr.Details.([]ValidationError); ok {
+		problem.Errors = LocalizeValidationErrors(locale, errs)
+	} else {
+		problem.Details = appErr.Details
 	}
 
-	return c.Status(status).JSON(response)
+	return c.Status(appErr.Status).JSON(problem, MIMEProblemJSON)
 }
 
 func HandleSuccess(c fiber.Ctx, message string, data ...interface{}) error {
@@ -1,18 +1,68 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"net/mail"
+	"net/url"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	// Time zones are validated against the embedded database, so results
+	// do not depend on the host having zoneinfo installed.
+	_ "time/tzdata"
+	"unicode/utf8"
 
+	"github.com/ElvinEga/gofiber_starter/apperror"
+	"github.com/ElvinEga/gofiber_starter/i18n"
+	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 )
 
 // ValidationError represents a single validation error
 type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
+	Field   string                 `json:"field"`
+	Code    string                 `json:"code,omitempty"`
+	Message string                 `json:"message"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
+
+// NewValidationError builds a validation error for field from a message code,
+// with the message rendered in the default locale.
+func NewValidationError(field, code string, params map[string]interface{}) ValidationError {
+	return ValidationError{
+		Field:   field,
+		Code:    code,
+		Message: i18n.T(i18n.DefaultLocale, code, params),
+		Params:  params,
+	}
+}
+
+// ruleError is a failed validation rule, identified by its message code.
+type ruleError struct {
+	code   string
+	params map[string]interface{}
+}
+
+func (e *ruleError) Error() string {
+	return i18n.T(i18n.DefaultLocale, e.code, e.params)
+}
+
+func failRule(code string, params map[string]interface{}) error {
+	return &ruleError{code: code, params: params}
+}
+
+// fieldError converts a rule failure into a ValidationError. Errors returned
+// by custom validators keep their own message.
+func fieldError(field string, err error) ValidationError {
+	var re *ruleError
+	if errors.As(err, &re) {
+		return NewValidationError(field, re.code, re.params)
+	}
+	return ValidationError{Field: field, Code: i18n.ValidationInvalid, Message: err.Error()}
 }
 
 // ValidationResult holds the result of validation
@@ -21,9 +71,28 @@ type ValidationResult struct {
 	Errors []ValidationError `json:"errors,omitempty"`
 }
 
-// Validator provides validation functionality
+// ErrInvalidPayload wraps request bodies that could not be decoded.
+var ErrInvalidPayload = errors.New("invalid payload")
+
+// Validator provides validation functionality.
+//
+// Rules are declared in the `validate` struct tag as a comma separated list:
+//
+//	required          value must not be the zero value
+//	email             RFC 5322 address
+//	min=N, max=N      length of strings, slices and maps; value of numbers
+//	oneof=a b c       value must be one of the space separated options
+//	uuid              string must be a UUID
+//	url               string must be an absolute http or https URL
+//	timezone          string must be an IANA time zone name
+//	eqfield=Field     value must equal the sibling field (e.g. password confirmation)
+//	regex=PATTERN     string must match PATTERN; must be the last rule
+//	dive              rules that follow apply to each slice element
+//
+// Nested structs and slices of structs are validated recursively. Fields that
+// are not required are skipped when empty. The legacy `required` tag and the
+// `validator` tag naming a custom validator are still honoured.
 type Validator struct {
-	// You can add custom validation functions here if needed
 	customValidators map[string]func(interface{}) error
 }
 
@@ -47,51 +116,289 @@ func (v *Validator) Validate(s interface{}) ValidationResult {
 	}
 
 	val := reflect.ValueOf(s)
-	if val.Kind() == reflect.Ptr {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return result
+		}
 		val = val.Elem()
 	}
+	if val.Kind() != reflect.Struct {
+		return result
+	}
 
+	v.validateStruct(val, "", &result)
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+func (v *Validator) validateStruct(val reflect.Value, prefix string, result *ValidationResult) {
 	typ := val.Type()
 
 	// Iterate through all fields
 	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
 		fieldType := typ.Field(i)
-
-		// Get the json tag name for the field
-		jsonTag := fieldType.Tag.Get("json")
-		if jsonTag == "" {
-			jsonTag = strings.ToLower(fieldType.Name)
+		if !fieldType.IsExported() {
+			continue
 		}
-		// Remove any json tag options (like ,omitempty)
-		jsonTag = strings.Split(jsonTag, ",")[0]
+		field := val.Field(i)
+		name := prefix + fieldName(fieldType)
+
+		rules, elemRules := parseRules(fieldType.Tag.Get("validate"))
+		required := v.isRequired(fieldType) || hasRule(rules, "required")
 
 		// Check required fields
-		if v.isRequired(fieldType) && v.isEmpty(field) {
-			result.Errors = append(result.Errors, ValidationError{
-				Field:   jsonTag,
-				Message: "This field is required",
-			})
-			result.Valid = false
+		if v.isEmpty(field) {
+			if required {
+				result.Errors = append(result.Errors, NewValidationError(name, i18n.ValidationRequired, nil))
+			}
+			// Skip validation for empty optional fields
 			continue
 		}
 
-		// Skip validation for empty optional fields
-		if v.isEmpty(field) {
+		failed := false
+		for _, r := range rules {
+			if r.name == "required" {
+				continue
+			}
+			if err := v.applyRule(r, field, val); err != nil {
+				result.Errors = append(result.Errors, fieldError(name, err))
+				failed = true
+				break
+			}
+		}
+		if failed {
 			continue
 		}
 
 		// Validate field based on its type
 		if err := v.validateField(field, fieldType); err != nil {
-			result.Errors = append(result.Errors, ValidationError{
-				Field:   jsonTag,
-				Message: err.Error(),
-			})
-			result.Valid = false
+			result.Errors = append(result.Errors, fieldError(name, err))
+			continue
 		}
+
+		v.validateNested(field, name, elemRules, result)
 	}
+}
 
-	return result
+// validateNested descends into struct fields and slice elements.
+func (v *Validator) validateNested(field reflect.Value, name string, elemRules []rule, result *ValidationResult) {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Struct:
+		if isScalarStruct(field.Type()) {
+			return
+		}
+		v.validateStruct(field, name+".", result)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < field.Len(); i++ {
+			elem := field.Index(i)
+			elemName := fmt.Sprintf("%s[%d]", name, i)
+			failed := false
+			for _, r := range elemRules {
+				if r.name == "required" {
+					if v.isEmpty(elem) {
+						result.Errors = append(result.Errors, NewValidationError(elemName, i18n.ValidationRequired, nil))
+						failed = true
+						break
+					}
+					continue
+				}
+				if err := v.applyRule(r, elem, reflect.Value{}); err != nil {
+					result.Errors = append(result.Errors, fieldError(elemName, err))
+					failed = true
+					break
+				}
+			}
+			if !failed {
+				v.validateNested(elem, elemName, nil, result)
+			}
+		}
+	}
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+// parseRules splits a validate tag into field rules and, after "dive",
+// element rules.
+func parseRules(tag string) (rules []rule, elemRules []rule) {
+	target := &rules
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			// The pattern may contain commas, so it consumes the rest of the tag.
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if part == "dive" {
+			target = &elemRules
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		*target = append(*target, rule{name: name, param: param})
+	}
+	return rules, elemRules
+}
+
+func hasRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	regexCache sync.Map
+	scalarType = map[reflect.Type]bool{
+		reflect.TypeOf(time.Time{}): true,
+		reflect.TypeOf(uuid.UUID{}): true,
+	}
+)
+
+func isScalarStruct(t reflect.Type) bool {
+	return scalarType[t]
+}
+
+// applyRule checks a single rule; parent is the enclosing struct for
+// cross-field rules.
+func (v *Validator) applyRule(r rule, field reflect.Value, parent reflect.Value) error {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	switch r.name {
+	case "email":
+		s := fmt.Sprint(field.Interface())
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return failRule(i18n.ValidationEmail, nil)
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s rule %q", r.name, r.param)
+		}
+		size, isLength := measure(field)
+		if r.name == "min" && size < limit {
+			if isLength {
+				return failRule(i18n.ValidationMinLength, map[string]interface{}{"min": r.param})
+			}
+			return failRule(i18n.ValidationMin, map[string]interface{}{"min": r.param})
+		}
+		if r.name == "max" && size > limit {
+			if isLength {
+				return failRule(i18n.ValidationMaxLength, map[string]interface{}{"max": r.param})
+			}
+			return failRule(i18n.ValidationMax, map[string]interface{}{"max": r.param})
+		}
+	case "oneof":
+		s := fmt.Sprint(field.Interface())
+		for _, option := range strings.Fields(r.param) {
+			if s == option {
+				return nil
+			}
+		}
+		return failRule(i18n.ValidationOneOf, map[string]interface{}{"options": strings.Join(strings.Fields(r.param), ", ")})
+	case "uuid":
+		if _, err := uuid.Parse(fmt.Sprint(field.Interface())); err != nil {
+			return failRule(i18n.ValidationUUID, nil)
+		}
+	case "url":
+		u, err := url.Parse(fmt.Sprint(field.Interface()))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return failRule(i18n.ValidationURL, nil)
+		}
+	case "timezone":
+		name := fmt.Sprint(field.Interface())
+		if _, err := time.LoadLocation(name); err != nil || name == "Local" {
+			return failRule(i18n.ValidationTimezone, nil)
+		}
+	case "regex":
+		re, err := compileRegex(r.param)
+		if err != nil {
+			return fmt.Errorf("invalid regex rule %q", r.param)
+		}
+		if !re.MatchString(fmt.Sprint(field.Interface())) {
+			return failRule(i18n.ValidationRegex, nil)
+		}
+	case "eqfield":
+		if !parent.IsValid() {
+			return nil
+		}
+		other := parent.FieldByName(r.param)
+		if !other.IsValid() {
+			return fmt.Errorf("unknown field %q in eqfield rule", r.param)
+		}
+		if !reflect.DeepEqual(field.Interface(), other.Interface()) {
+			otherField, _ := parent.Type().FieldByName(r.param)
+			return failRule(i18n.ValidationEqField, map[string]interface{}{"field": fieldName(otherField)})
+		}
+	default:
+		if fn, ok := v.customValidators[r.name]; ok {
+			return fn(field.Interface())
+		}
+		return fmt.Errorf("unknown validation rule %q", r.name)
+	}
+	return nil
+}
+
+// measure returns the length of strings and collections, or the numeric value.
+func measure(field reflect.Value) (float64, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(field.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(field.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), false
+	}
+	return 0, false
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// fieldName returns the json name of a struct field.
+func fieldName(field reflect.StructField) string {
+	// Get the json tag name for the field
+	jsonTag := field.Tag.Get("json")
+	// Remove any json tag options (like ,omitempty)
+	jsonTag = strings.Split(jsonTag, ",")[0]
+	if jsonTag == "" || jsonTag == "-" {
+		jsonTag = strings.ToLower(field.Name)
+	}
+	return jsonTag
 }
 
 // isRequired checks if a field is required based on struct tags
@@ -113,14 +420,23 @@ func (v *Validator) isEmpty(field reflect.Value) bool {
 		return field.String() == ""
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return field.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field.Uint() == 0
 	case reflect.Float32, reflect.Float64:
 		return field.Float() == 0
+	case reflect.Bool:
+		return !field.Bool()
+	case reflect.Slice, reflect.Map:
+		return field.Len() == 0
 	case reflect.Struct:
 		if field.Type() == reflect.TypeOf(time.Time{}) {
 			return field.Interface().(time.Time).IsZero()
 		}
+		if field.Type() == reflect.TypeOf(uuid.UUID{}) {
+			return field.Interface().(uuid.UUID) == uuid.Nil
+		}
 		return false
-	case reflect.Ptr:
+	case reflect.Ptr, reflect.Interface:
 		return field.IsNil()
 	default:
 		return false
@@ -129,25 +445,19 @@ func (v *Validator) isEmpty(field reflect.Value) bool {
 
 // validateField validates a single field based on its type and tags
 func (v *Validator) validateField(field reflect.Value, fieldType reflect.StructField) error {
-	switch field.Interface().(type) {
-	case uuid.UUID:
-		if field.Interface().(uuid.UUID) == uuid.Nil {
-			return fmt.Errorf("invalid UUID format")
-		}
-	case string:
-		if maxLen, ok := fieldType.Tag.Lookup("max"); ok {
-			// Add string length validation
-			_ = maxLen // You can implement this
-		}
-	case float64:
-		if min, ok := fieldType.Tag.Lookup("min"); ok {
-			// Add minimum value validation
-			_ = min // You can implement this
+	if !field.CanInterface() {
+		return nil
+	}
+
+	// Legacy min/max tags
+	if maxValue, ok := fieldType.Tag.Lookup("max"); ok {
+		if err := v.applyRule(rule{name: "max", param: maxValue}, field, reflect.Value{}); err != nil {
+			return err
 		}
-	case time.Time:
-		// Add any specific time validation if needed
-		if field.Interface().(time.Time).IsZero() {
-			return fmt.Errorf("invalid date format")
+	}
+	if minValue, ok := fieldType.Tag.Lookup("min"); ok {
+		if err := v.applyRule(rule{name: "min", param: minValue}, field, reflect.Value{}); err != nil {
+			return err
 		}
 	}
 
@@ -162,3 +472,19 @@ func (v *Validator) validateField(field reflect.Value, fieldType reflect.StructF
 
 	return nil
 }
+
+var defaultValidator = NewValidator()
+
+// BindAndValidate decodes the request body into out and validates it. It
+// returns a 400 apperror wrapping ErrInvalidPayload when the body cannot be
+// decoded and a 422 apperror listing the failures when the payload breaks its
+// validation rules.
+func BindAndValidate(c fiber.Ctx, out interface{}) error {
+	if err := c.Bind().Body(out); err != nil {
+		return apperror.BadRequest(i18n.ErrInvalidPayload).Wrap(fmt.Errorf("%w: %v", ErrInvalidPayload, err))
+	}
+	if result := defaultValidator.Validate(out); !result.Valid {
+		return apperror.Unprocessable(i18n.ErrValidationFailed, result.Errors)
+	}
+	return nil
+}